@@ -0,0 +1,354 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/events"
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
+	"github.com/posener/complete"
+)
+
+type ApplyCommand struct {
+	Meta                Meta
+	FlagContinueOnError bool
+	FlagPrune           bool
+	FlagAutoApprove     bool
+	ioDir               string
+}
+
+// applyResult is the outcome of one inventory file, or of one write of a
+// saved plan
+type applyResult struct {
+	Kind   string `json:"kind"`
+	File   string `json:"file"`
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// applyReport is the result apply prints
+type applyReport struct {
+	Resources []applyResult `json:"resources"`
+	Applied   int           `json:"applied"`
+	Failed    int           `json:"failed"`
+	Pruned    *pruneReport  `json:"pruned,omitempty"`
+}
+
+// applyKind is an inventory directory with the put used to reconcile one of
+// its files, the plan used to preview it and, when a planned change is more
+// than a single write, the function that executes a saved change
+type applyKind struct {
+	Dir   string
+	Put   func(f string) error
+	Plan  func(f string) ([]*plan.Change, error)
+	Write func(change *plan.Change) error
+}
+
+func (c *ApplyCommand) Help() string {
+	helpText := `
+Usage: vault-cli apply [options] [filespec | planfile]
+
+  Applies every kind found in the context inventory in dependency order:
+  vaultnamespace (parent first), vaultendpoint, vaultauth, vaultpolicy,
+  vaultrole, jwtrole, pkirole, sshrole and, when -dir is set, secretmeta.
+  filespec defaults to "*" and is matched against the files of every kind.
+
+  When given a plan file saved with "vault-cli plan -out", apply executes
+  exactly the writes in the plan. It refuses to write anything if the
+  inventory or any planned object in vault changed since the plan was saved.
+
+Apply Options:
+  -auto-approve
+    Prune without asking for confirmation.
+
+  -continue-on-error
+    Keep applying the remaining resources when one fails. By default apply
+    stops at the first failure.
+
+  -dir=<directory>
+    Directory holding one file per secretmeta key. secretmeta files are only
+    applied when this is set.
+
+  -prune
+    After every resource applied successfully, delete the vault objects this
+    context put that the inventory no longer declares, see "vault-cli prune -h". Cannot be used with
+    a filespec or a plan file.
+
+  ` + eventOptionsUsage() + `
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ApplyCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(), complete.Flags{
+		"-auto-approve":      complete.PredictNothing,
+		"-continue-on-error": complete.PredictNothing,
+		"-dir":               complete.PredictDirs("*"),
+		"-prune":             complete.PredictNothing,
+	})
+}
+
+func (c *ApplyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ApplyCommand) Synopsis() string {
+	return "apply reconciles every inventory kind in dependency order"
+}
+
+func (c *ApplyCommand) Name() string { return "apply" }
+
+func (c *ApplyCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagContinueOnError, "continue-on-error", false, "")
+	flagSet.StringVar(&c.ioDir, "dir", "", "")
+	flagSet.BoolVar(&c.FlagPrune, "prune", false, "")
+	flagSet.BoolVar(&c.FlagAutoApprove, "auto-approve", false, "")
+	c.Meta.eventFlags(flagSet)
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	filespec := "*"
+	if len(args) > 0 {
+		filespec = args[0]
+	}
+	if c.FlagPrune && filespec != "*" {
+		fmt.Fprintf(os.Stderr, "-prune compares the whole inventory and cannot be used with %s\n", filespec)
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+	defer c.Meta.closeEvents()
+
+	if info, err := os.Stat(filespec); err == nil && !info.IsDir() {
+		return c.applyPlanFile(filespec)
+	}
+
+	// remember the namespace from the context so every resource starts from it
+	defaultNamespace := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+
+	report := &applyReport{Resources: []applyResult{}}
+	for _, kind := range c.kinds() {
+		files, err := c.inventoryFiles(kind, filespec)
+		if err != nil {
+			fmt.Printf("%s\n", err)
+			return 1
+		}
+		for _, f := range files {
+			c.Meta.SecretService.GetClient().SetNamespace(defaultNamespace)
+			action, err := c.Meta.putFile(kind.Dir, f, kind.Put, kind.Plan)
+			if err != nil {
+				report.add(applyResult{Kind: kind.Dir, File: f, Status: "failed", Error: err.Error()})
+				c.Meta.infof("%s: (%s) %s\n", kind.Dir, f, err)
+				if !c.FlagContinueOnError {
+					return c.printReport(report)
+				}
+				continue
+			}
+			report.add(applyResult{Kind: kind.Dir, File: f, Status: string(action)})
+		}
+	}
+
+	if report.Failed == 0 && c.FlagPrune {
+		c.Meta.SecretService.GetClient().SetNamespace(defaultNamespace)
+		report.Pruned = c.prune(c.FlagAutoApprove)
+	}
+	return c.printReport(report)
+}
+
+// applyPlanFile verifies every change in a saved plan against a fresh plan of
+// the same inventory and then executes the saved writes in order
+func (c *ApplyCommand) applyPlanFile(filename string) int {
+	saved, err := plan.Load(filename)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if saved.Context != c.Meta.CurrentContext.Name {
+		fmt.Printf("plan was saved for context %s, not %s\n", saved.Context, c.Meta.CurrentContext.Name)
+		return 1
+	}
+
+	changes, err := c.planInventory(saved.Filespec)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	live := map[string]*plan.Change{}
+	for _, change := range changes {
+		live[change.Key()] = change
+	}
+	for _, change := range saved.Changes {
+		if err := change.Verify(live[change.Key()]); err != nil {
+			fmt.Printf("%s\nrun plan again to review the current changes\n", err)
+			return 1
+		}
+	}
+
+	writes := map[string]func(change *plan.Change) error{}
+	for _, kind := range c.kinds() {
+		writes[kind.Dir] = kind.Write
+	}
+	report := &applyReport{Resources: []applyResult{}}
+	for _, change := range saved.Changes {
+		if change.Action == plan.ActionNoChange {
+			continue
+		}
+		write := writes[change.Kind]
+		if write == nil {
+			write = c.Meta.writeChange
+		}
+		start := time.Now()
+		err := write(change)
+		e := &events.Event{
+			Time:      start.UTC(),
+			Kind:      change.Kind,
+			File:      change.File,
+			Namespace: export.Namespace(change.Namespace),
+			Path:      change.Path,
+			Action:    changeAction(change),
+			Duration:  time.Since(start).Seconds(),
+		}
+		if err != nil {
+			e.Action, e.Error = events.ActionFailed, err.Error()
+		}
+		c.Meta.record(e)
+		if err != nil {
+			report.add(applyResult{Kind: change.Kind, File: change.File, Path: change.Path, Status: string(e.Action), Error: err.Error()})
+			c.Meta.infof("%s: (%s) %s %s\n", change.Kind, change.File, change.Path, err)
+			return c.printReport(report)
+		}
+		report.add(applyResult{Kind: change.Kind, File: change.File, Path: change.Path, Status: string(e.Action)})
+	}
+	return c.printReport(report)
+}
+
+// kinds returns the inventory kinds in the order they must be applied
+func (c *ApplyCommand) kinds() []applyKind {
+	namespace := &PutVaultNamespaceCommand{Meta: c.Meta}
+	endpoint := &PutVaultEndpointCommand{Meta: c.Meta}
+	auth := &PutVaultAuthCommand{Meta: c.Meta}
+	policy := &PutVaultPolicyCommand{Meta: c.Meta}
+	role := &PutVaultRoleCommand{Meta: c.Meta}
+	jwtrole := &PutJWTRoleCommand{Meta: c.Meta}
+	pkirole := &PutPKIRoleCommand{Meta: c.Meta}
+	sshrole := &PutSSHRoleCommand{Meta: c.Meta}
+	kinds := []applyKind{
+		{Dir: "vaultnamespace", Put: namespace.Put, Plan: namespace.Plan},
+		{Dir: "vaultendpoint", Put: endpoint.Put, Plan: endpoint.Plan, Write: endpoint.WriteChange},
+		{Dir: "vaultauth", Put: auth.Put, Plan: auth.Plan},
+		{Dir: "vaultpolicy", Put: policy.Put, Plan: policy.Plan},
+		{Dir: "vaultrole", Put: role.Put, Plan: role.Plan},
+		{Dir: "jwtrole", Put: jwtrole.Put, Plan: jwtrole.Plan},
+		{Dir: "pkirole", Put: pkirole.Put, Plan: pkirole.Plan},
+		{Dir: "sshrole", Put: sshrole.Put, Plan: sshrole.Plan},
+	}
+	if c.ioDir != "" {
+		secret := &PutSecretCommand{Meta: c.Meta, ioDir: c.ioDir}
+		kinds = append(kinds, applyKind{
+			Dir: "secretmeta",
+			Put: func(f string) error {
+				_, err := secret.Put(f, nil)
+				return err
+			},
+			Plan: func(f string) ([]*plan.Change, error) {
+				return secret.Plan(f, nil)
+			},
+		})
+	}
+	return kinds
+}
+
+// inventoryFiles returns the files of kind matching filespec, with
+// namespaces ordered parent first
+func (c *ApplyCommand) inventoryFiles(kind applyKind, filespec string) ([]string, error) {
+	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/"+kind.Dir+"/", filespec)
+	if err != nil {
+		return nil, fmt.Errorf("get files error: %s", err.Error())
+	}
+	if kind.Dir == "vaultnamespace" {
+		return c.orderNamespaces(files)
+	}
+	return files, nil
+}
+
+// orderNamespaces sorts namespace files so that a namespace is created
+// before any namespace based on it
+func (c *ApplyCommand) orderNamespaces(files []string) ([]string, error) {
+	put := &PutVaultNamespaceCommand{Meta: c.Meta}
+	depth := map[string]int{}
+	for _, f := range files {
+		ns, err := put.Render(f)
+		if err != nil {
+			return nil, fmt.Errorf("vaultnamespace: (%s) %s", f, err)
+		}
+		depth[f] = namespaceDepth(ns.Spec.NamespaceBase, ns.Spec.NamespaceName)
+	}
+	ordered := append([]string{}, files...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return depth[ordered[i]] < depth[ordered[j]]
+	})
+	return ordered, nil
+}
+
+// namespaceDepth returns the number of path elements of the namespace
+// name created under base, root being depth zero
+func namespaceDepth(base, name string) int {
+	path := strings.Trim(name, "/")
+	base = strings.Trim(base, "/")
+	if base != "" && base != "root" {
+		path = base + "/" + path
+	}
+	return strings.Count(path, "/") + 1
+}
+
+func (r *applyReport) add(result applyResult) {
+	r.Resources = append(r.Resources, result)
+	if result.Status == "failed" {
+		r.Failed++
+	} else {
+		r.Applied++
+	}
+}
+
+// printReport prints the report in the -output format and returns the exit
+// code of the apply
+func (c *ApplyCommand) printReport(report *applyReport) int {
+	err := c.Meta.output(report, func(w io.Writer) error {
+		fmt.Fprintf(w, "Apply complete: %d applied, %d failed\n", report.Applied, report.Failed)
+		if report.Pruned != nil {
+			report.Pruned.printSummary(w)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if report.Failed > 0 || (report.Pruned != nil && !report.Pruned.ok()) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package command_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ibm/vault-cli/command"
+	"github.com/ibm/vault-cli/pkg/configservice/configfile"
+	"github.com/ibm/vault-cli/pkg/templateservice/template"
+	"github.com/mitchellh/cli"
+)
+
+// inventoryConfigYAML is the config of a context dev of a vault and an
+// inventory, with a token user
+const inventoryConfigYAML = `apiVersion: v1
+kind: Config
+contexts:
+- name: dev
+  context:
+    cluster: local
+    inventoryPath: %s
+    namespace: root
+    user: root
+clusters:
+- name: local
+  cluster:
+    server: %s
+current-context: dev
+users:
+- name: root
+  user:
+    token: s.root
+`
+
+const applyPolicy = `apiVersion: api.gensec.ibm.com/v1
+kind: VaultPolicy
+metadata:
+  name: operator
+spec:
+  policyName: operator
+  policies:
+    paths:
+      - capabilities:
+          - read
+        path: secret/*
+`
+
+// inventoryConfig writes the files of an inventory, by their path in it,
+// and the config of its context dev of the vault at url to a temporary
+// directory and returns the directory and the config path
+func inventoryConfig(t *testing.T, url string, files map[string]string) (string, string) {
+	dir, err := ioutil.TempDir("", "vault-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory := filepath.Join(dir, "inventory")
+	for path, content := range files {
+		path = filepath.Join(inventory, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(fmt.Sprintf(inventoryConfigYAML, inventory, url)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, configPath
+}
+
+// runCommand runs the named command with args and returns its exit code,
+// the lines it wrote to os.Stdout and its ui errors. Tests that run it
+// cannot be parallel.
+func runCommand(t *testing.T, name string, args ...string) (int, []string, string) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	lines := make(chan []string)
+	go func() {
+		var read []string
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			read = append(read, scanner.Text())
+		}
+		lines <- read
+	}()
+
+	meta := &command.Meta{
+		ConfigService:   configfile.NewConfigFileService(),
+		TemplateService: template.MakeTemplateService(),
+	}
+	ui := cli.NewMockUi()
+	cmd, err := command.Commands(meta, ui)[name]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := cmd.Run(args)
+	w.Close()
+	return code, <-lines, ui.ErrorWriter.String()
+}
+
+func TestApplyEventsOnStdout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"vaultpolicy/operator.yaml": applyPolicy})
+	defer os.RemoveAll(dir)
+
+	code, read, errors := runCommand(t, "apply", "-config", configPath, "-events", "-", "-auto-approve")
+	if code != 0 {
+		t.Fatalf("apply failed with %d: %s", code, errors)
+	}
+
+	// stdout holds the events only, the summary goes to stderr
+	if len(read) != 1 {
+		t.Fatalf("expected one event on stdout, got %q", read)
+	}
+	for _, line := range read {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Errorf("%q is not an event: %s", line, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"os"
+	"strings"
 
 	colorable "github.com/mattn/go-colorable"
 	"github.com/mitchellh/cli"
@@ -34,11 +35,173 @@ func Commands(metaPtr *Meta, agentUI cli.Ui) map[string]cli.CommandFactory {
 	}
 
 	all := map[string]cli.CommandFactory{
+		"apply": func() (cli.Command, error) {
+			return &ApplyCommand{
+				Meta: meta,
+			}, nil
+		},
 		"config": func() (cli.Command, error) {
 			return &ConfigCommand{
 				Meta: meta,
 			}, nil
 		},
+		"config current-context": func() (cli.Command, error) {
+			return &ConfigCurrentContextCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config delete-cluster": func() (cli.Command, error) {
+			return &ConfigDeleteCommand{
+				Meta: meta,
+				Kind: "cluster",
+			}, nil
+		},
+		"config delete-context": func() (cli.Command, error) {
+			return &ConfigDeleteCommand{
+				Meta: meta,
+				Kind: "context",
+			}, nil
+		},
+		"config delete-user": func() (cli.Command, error) {
+			return &ConfigDeleteCommand{
+				Meta: meta,
+				Kind: "user",
+			}, nil
+		},
+		"config get-contexts": func() (cli.Command, error) {
+			return &ConfigGetContextsCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config migrate-secrets": func() (cli.Command, error) {
+			return &ConfigMigrateSecretsCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config set-cluster": func() (cli.Command, error) {
+			return &ConfigSetClusterCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config set-context": func() (cli.Command, error) {
+			return &ConfigSetContextCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config set-user": func() (cli.Command, error) {
+			return &ConfigSetUserCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config use-context": func() (cli.Command, error) {
+			return &ConfigUseContextCommand{
+				Meta: meta,
+			}, nil
+		},
+		"config view": func() (cli.Command, error) {
+			return &ConfigViewCommand{
+				Meta: meta,
+			}, nil
+		},
+		"delete": func() (cli.Command, error) {
+			return &DeleteCommand{
+				Meta: meta,
+			}, nil
+		},
+		"delete jwtrole": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "jwtrole",
+			}, nil
+		},
+		"delete pkirole": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "pkirole",
+			}, nil
+		},
+		"delete secret": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "secret",
+			}, nil
+		},
+		"delete sshrole": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "sshrole",
+			}, nil
+		},
+		"delete vaultauth": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "vaultauth",
+			}, nil
+		},
+		"delete vaultendpoint": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "vaultendpoint",
+			}, nil
+		},
+		"delete vaultnamespace": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "vaultnamespace",
+			}, nil
+		},
+		"delete vaultpolicy": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "vaultpolicy",
+			}, nil
+		},
+		"delete vaultrole": func() (cli.Command, error) {
+			return &DeleteKindCommand{
+				Meta: meta,
+				Kind: "vaultrole",
+			}, nil
+		},
+		"describe": func() (cli.Command, error) {
+			return &DescribeCommand{
+				Meta: meta,
+			}, nil
+		},
+		"export": func() (cli.Command, error) {
+			return &ExportCommand{
+				Meta: meta,
+			}, nil
+		},
+		"get": func() (cli.Command, error) {
+			return &GetCommand{
+				Meta: meta,
+			}, nil
+		},
+		"login": func() (cli.Command, error) {
+			return &LoginCommand{
+				Meta: meta,
+			}, nil
+		},
+		"logout": func() (cli.Command, error) {
+			return &LogoutCommand{
+				Meta: meta,
+			}, nil
+		},
+		"owned": func() (cli.Command, error) {
+			return &OwnedCommand{
+				Meta: meta,
+			}, nil
+		},
+		"plan": func() (cli.Command, error) {
+			return &PlanCommand{
+				Meta: meta,
+			}, nil
+		},
+		"prune": func() (cli.Command, error) {
+			return &PruneCommand{
+				Meta: meta,
+			}, nil
+		},
 		"put": func() (cli.Command, error) {
 			return &PutCommand{
 				Meta: meta,
@@ -89,11 +252,28 @@ func Commands(metaPtr *Meta, agentUI cli.Ui) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"status": func() (cli.Command, error) {
+			return &StatusCommand{
+				Meta: meta,
+			}, nil
+		},
+		"whoami": func() (cli.Command, error) {
+			return &WhoamiCommand{
+				Meta: meta,
+			}, nil
+		},
 	}
 
 	for k, v := range EntCommands(metaPtr, agentUI) {
 		all[k] = v
 	}
 
+	// put, apply and plan run in every context of a -context list or glob
+	for name, factory := range all {
+		if name == "apply" || name == "plan" || strings.HasPrefix(name, "put ") {
+			all[name] = fanOutFactory(meta, name, factory)
+		}
+	}
+
 	return all
 }
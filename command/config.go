@@ -12,12 +12,24 @@ type ConfigCommand struct {
 
 func (f *ConfigCommand) Help() string {
 	helpText := `
-Usage: vaultcli config <subcommand> [options] [args]
-  This command groups subcommands for interacting with Config system.
-  Users can bootstrap vaultcli's Config system, create policies that restrict access,
-  and generate tokens from those policies.
-  Bootstrap Configs:
-      $ vaultcli config bootstrap
+Usage: vault-cli config <subcommand> [options] [args]
+
+  This command groups subcommands that read and edit the vault-cli config
+  file, "~/.vaultcli/config.yaml" unless -config or VAULTCLICONFIG is set.
+
+  Subcommands:
+    view              print the config, secrets redacted
+    set-cluster       create or update a cluster
+    set-user          create or update a cert, userpass or approle user
+    set-context       create or update a context
+    use-context       set the current context
+    get-contexts      list the contexts
+    current-context   print the current context
+    delete-cluster    delete a cluster
+    delete-user       delete a user
+    delete-context    delete a context
+    migrate-secrets   move plaintext secrets to the credential store
+
   Please see the individual subcommand help for detailed usage information.
 `
 	return strings.TrimSpace(helpText)
@@ -32,3 +44,17 @@ func (f *ConfigCommand) Name() string { return "config" }
 func (f *ConfigCommand) Run(args []string) int {
 	return cli.RunResultHelp
 }
+
+// saveConfig writes Config back to the config file at path
+func (m *Meta) saveConfig(path string) error {
+	return m.ConfigService.Write(path, m.Config)
+}
+
+// contextNames returns the names of the contexts in Config
+func (m *Meta) contextNames() []string {
+	names := []string{}
+	for _, ctx := range m.Config.Contexts {
+		names = append(names, ctx.Name)
+	}
+	return names
+}
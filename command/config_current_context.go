@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type ConfigCurrentContextCommand struct {
+	Meta Meta
+}
+
+func (c *ConfigCurrentContextCommand) Help() string {
+	helpText := `
+Usage: vault-cli config current-context [options]
+
+  Prints the current context of the config file.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigCurrentContextCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *ConfigCurrentContextCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigCurrentContextCommand) Synopsis() string {
+	return "config current-context prints the current context"
+}
+
+func (c *ConfigCurrentContextCommand) Name() string { return "config current-context" }
+
+func (c *ConfigCurrentContextCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	_, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	if c.Meta.Config.CurrentContext == "" {
+		fmt.Fprintf(os.Stderr, "current-context is not set\n")
+		return 1
+	}
+	c.Meta.Ui.Output(c.Meta.Config.CurrentContext)
+	return 0
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+// ConfigDeleteCommand deletes a cluster, user or context from the config
+// file
+type ConfigDeleteCommand struct {
+	Meta Meta
+	// Kind is cluster, user or context
+	Kind string
+}
+
+func (c *ConfigDeleteCommand) Help() string {
+	helpText := `
+Usage: vault-cli config delete-` + c.Kind + ` [options] <name>
+
+  Deletes the named ` + c.Kind + ` from the config file. The current context, and the
+  cluster and user it uses, cannot be deleted.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigDeleteCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *ConfigDeleteCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictAnything
+}
+
+func (c *ConfigDeleteCommand) Synopsis() string {
+	return "config delete-" + c.Kind + " deletes a " + c.Kind
+}
+
+func (c *ConfigDeleteCommand) Name() string { return "config delete-" + c.Kind }
+
+func (c *ConfigDeleteCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <name>")
+		return 1
+	}
+	name := args[0]
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	switch c.Kind {
+	case "cluster":
+		err = c.Meta.Config.DeleteCluster(name)
+	case "user":
+		err = c.Meta.Config.DeleteUser(name)
+	case "context":
+		err = c.Meta.Config.DeleteContext(name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to delete %s %q: %s\n", c.Kind, name, err)
+		return 1
+	}
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("Deleted %s %q", c.Kind, name))
+	return 0
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/posener/complete"
+)
+
+type ConfigGetContextsCommand struct {
+	Meta Meta
+}
+
+func (c *ConfigGetContextsCommand) Help() string {
+	helpText := `
+Usage: vault-cli config get-contexts [options]
+
+  Lists the contexts of the config file with their cluster, user, vault
+  namespace and inventory path. The current context is marked with a "*".
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigGetContextsCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *ConfigGetContextsCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigGetContextsCommand) Synopsis() string {
+	return "config get-contexts lists the contexts"
+}
+
+func (c *ConfigGetContextsCommand) Name() string { return "config get-contexts" }
+
+// contextRow is a context as get-contexts prints it
+type contextRow struct {
+	Current       bool   `json:"current"`
+	Name          string `json:"name"`
+	Cluster       string `json:"cluster"`
+	User          string `json:"user"`
+	Namespace     string `json:"namespace"`
+	InventoryPath string `json:"inventoryPath"`
+}
+
+func (c *ConfigGetContextsCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	_, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	contexts := []contextRow{}
+	for _, ctx := range c.Meta.Config.Contexts {
+		contexts = append(contexts, contextRow{
+			Current:       ctx.Name == c.Meta.Config.CurrentContext,
+			Name:          ctx.Name,
+			Cluster:       ctx.Cluster,
+			User:          ctx.User,
+			Namespace:     ctx.Namespace,
+			InventoryPath: ctx.InventoryPath,
+		})
+	}
+	err = c.Meta.output(contexts, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, ctx := range contexts {
+			current := ""
+			if ctx.Current {
+				current = "*"
+			}
+			rows = append(rows, []string{current, ctx.Name, ctx.Cluster, ctx.User, ctx.Namespace, ctx.InventoryPath})
+		}
+		return output.Table(w, []string{"CURRENT", "NAME", "CLUSTER", "USER", "NAMESPACE", "INVENTORY"}, rows)
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
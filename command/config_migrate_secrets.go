@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/credstore"
+	"github.com/posener/complete"
+)
+
+type ConfigMigrateSecretsCommand struct {
+	Meta      Meta
+	FlagStore string
+}
+
+func (c *ConfigMigrateSecretsCommand) Help() string {
+	helpText := `
+Usage: vault-cli config migrate-secrets [options]
+
+  Moves the plaintext session tokens, passwords and secret ids of the config
+  file to the credential store. The config file keeps references to them,
+  e.g. "password-ref: keyring:vaultcli/user/alice/password". Secrets that
+  already have a reference are stored under it.
+
+  The keyring store is the secret service of the OS, secret-tool on linux
+  and security on macOS. Where it cannot be reached secrets go to the file
+  store, "credentials.enc" next to the config file, encrypted with a
+  passphrase that is asked or taken from ` + credstore.EnvPassphrase + `.
+
+Migrate Secrets Options:
+  -store=<keyring|file>
+    The credential store to move the secrets to. Defaults to "keyring".
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigMigrateSecretsCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
+		complete.Flags{
+			"-store": complete.PredictSet("keyring", "file"),
+		})
+}
+
+func (c *ConfigMigrateSecretsCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigMigrateSecretsCommand) Synopsis() string {
+	return "config migrate-secrets moves plaintext secrets to the credential store"
+}
+
+func (c *ConfigMigrateSecretsCommand) Name() string { return "config migrate-secrets" }
+
+func (c *ConfigMigrateSecretsCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.FlagStore, "store", "keyring", "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+	if c.FlagStore != "keyring" && c.FlagStore != "file" {
+		c.Meta.Ui.Error(fmt.Sprintf("unknown credential store %s, expected keyring or file", c.FlagStore))
+		return 1
+	}
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	moved, err := c.Meta.Config.StoreSecrets(c.FlagStore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	if moved == 0 {
+		c.Meta.Ui.Output("No plaintext secrets to migrate")
+		return 0
+	}
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("Moved %d secrets to the %s credential store", moved, c.FlagStore))
+	c.Meta.Ui.Output("The .bak backups of the config files still hold them in plaintext, remove them once the migrated config works")
+	return 0
+}
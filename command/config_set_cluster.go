@@ -0,0 +1,110 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type ConfigSetClusterCommand struct {
+	Meta                      Meta
+	FlagServer                string
+	FlagCertAuth              string
+	FlagCertAuthData          string
+	FlagInsecureSkipTLSVerify bool
+}
+
+func (c *ConfigSetClusterCommand) Help() string {
+	helpText := `
+Usage: vault-cli config set-cluster [options] <name>
+
+  Creates the named cluster or updates the fields given as options.
+
+Set Cluster Options:
+  -server=<url>
+    The address of the vault server, e.g. https://vault.example.com:8200.
+
+  -certificate-authority=<file>
+    The CA certificate file to verify the server with.
+
+  -certificate-authority-data=<pem>
+    The CA certificate to verify the server with.
+
+  -insecure-skip-tls-verify
+    Do not verify the server certificate.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigSetClusterCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
+		complete.Flags{
+			"-server":                     complete.PredictAnything,
+			"-certificate-authority":      complete.PredictFiles("*"),
+			"-certificate-authority-data": complete.PredictAnything,
+			"-insecure-skip-tls-verify":   complete.PredictNothing,
+		})
+}
+
+func (c *ConfigSetClusterCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigSetClusterCommand) Synopsis() string {
+	return "config set-cluster creates or updates a cluster"
+}
+
+func (c *ConfigSetClusterCommand) Name() string { return "config set-cluster" }
+
+func (c *ConfigSetClusterCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.FlagServer, "server", "", "")
+	flagSet.StringVar(&c.FlagCertAuth, "certificate-authority", "", "")
+	flagSet.StringVar(&c.FlagCertAuthData, "certificate-authority-data", "", "")
+	flagSet.BoolVar(&c.FlagInsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <name>")
+		return 1
+	}
+	name := args[0]
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	// an existing cluster keeps its tls verification unless the flag is given
+	insecure := c.FlagInsecureSkipTLSVerify
+	if found := c.Meta.Config.GetClusterByName(name); found != nil && !flagGiven(flagSet, "insecure-skip-tls-verify") {
+		insecure = found.InsecureSkipTLSVerify
+	}
+	_, err = c.Meta.Config.SetCluster(name, c.FlagCertAuth, c.FlagCertAuthData, insecure, c.FlagServer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("Cluster %q set", name))
+	return 0
+}
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/posener/complete"
+)
+
+type ConfigSetContextCommand struct {
+	Meta              Meta
+	FlagCluster       string
+	FlagUser          string
+	FlagNamespace     string
+	FlagInventoryPath string
+	FlagOwnershipPath string
+}
+
+func (c *ConfigSetContextCommand) Help() string {
+	helpText := `
+Usage: vault-cli config set-context [options] <name>
+
+  Creates the named context or updates the fields given as options. The
+  session of an existing context is kept.
+
+Set Context Options:
+  -cluster=<name>
+    The cluster of the context, see "vault-cli config set-cluster".
+
+  -user=<name>
+    The user of the context, see "vault-cli config set-user".
+
+  -vault-namespace=<namespace>
+    The vault namespace commands run in, "root" for the root namespace.
+
+  -inventory-path=<directory>
+    The inventory directory put, plan and apply read.
+
+  -ownership-path=<kv path>
+    The kv-v2 path of the ownership index, see "vault-cli owned".
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigSetContextCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
+		complete.Flags{
+			"-cluster":         complete.PredictAnything,
+			"-user":            complete.PredictAnything,
+			"-vault-namespace": complete.PredictAnything,
+			"-inventory-path":  complete.PredictDirs("*"),
+			"-ownership-path":  complete.PredictAnything,
+		})
+}
+
+func (c *ConfigSetContextCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigSetContextCommand) Synopsis() string {
+	return "config set-context creates or updates a context"
+}
+
+func (c *ConfigSetContextCommand) Name() string { return "config set-context" }
+
+func (c *ConfigSetContextCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.FlagCluster, "cluster", "", "")
+	flagSet.StringVar(&c.FlagUser, "user", "", "")
+	flagSet.StringVar(&c.FlagNamespace, "vault-namespace", "", "")
+	flagSet.StringVar(&c.FlagInventoryPath, "inventory-path", "", "")
+	flagSet.StringVar(&c.FlagOwnershipPath, "ownership-path", "", "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <name>")
+		return 1
+	}
+	name := args[0]
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	if c.FlagCluster != "" && c.Meta.Config.GetClusterByName(c.FlagCluster) == nil {
+		fmt.Fprintf(os.Stderr, "cluster %q not found, create it with vault-cli config set-cluster\n", c.FlagCluster)
+		return 1
+	}
+	if c.FlagUser != "" && c.Meta.Config.GetUserByName(c.FlagUser) == nil {
+		fmt.Fprintf(os.Stderr, "user %q not found, create it with vault-cli config set-user\n", c.FlagUser)
+		return 1
+	}
+
+	// a nil session keeps the session of an existing context
+	var session *config.Session
+	if c.Meta.Config.GetContextByName(name) == nil {
+		session = &config.Session{}
+	}
+	ctx, err := c.Meta.Config.SetContext(name, c.FlagCluster, c.FlagNamespace, session, c.FlagUser)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	if c.FlagInventoryPath != "" {
+		ctx.InventoryPath = c.FlagInventoryPath
+	}
+	if c.FlagOwnershipPath != "" {
+		ctx.OwnershipPath = c.FlagOwnershipPath
+	}
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("Context %q set", name))
+	return 0
+}
@@ -0,0 +1,405 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/posener/complete"
+)
+
+type ConfigSetUserCommand struct {
+	Meta               Meta
+	FlagAuth           string
+	FlagClientCert     string
+	FlagClientCertData string
+	FlagClientKey      string
+	FlagClientKeyData  string
+	FlagAuthPath       string
+	FlagUsername       string
+	FlagPassword       string
+	FlagRoleID         string
+	FlagSecretID       string
+	FlagToken          string
+	FlagTokenFile      string
+	FlagTokenEnv       string
+	FlagTokenHelper    bool
+	FlagK8sRole        string
+	FlagK8sTokenPath   string
+	FlagK8sMount       string
+	FlagJWTRole        string
+	FlagJWTFile        string
+	FlagJWTEnv         string
+	FlagJWTMount       string
+	FlagOIDCRole       string
+	FlagOIDCMount      string
+	FlagOIDCListen     string
+	FlagOIDCNoBrowser  bool
+	FlagExecCommand    string
+	FlagExecArgs       []string
+	FlagExecEnv        []config.ExecEnvVar
+}
+
+func (c *ConfigSetUserCommand) Help() string {
+	helpText := `
+Usage: vault-cli config set-user [options] <name>
+
+  Creates the named user or updates the credentials given as options. A
+  user logs in with one auth method: cert, userpass, ldap, okta, radius,
+  approle, kubernetes, jwt, oidc or exec, or uses an existing token. The
+  method is taken from -auth or else from the credential options given,
+  which must all be of one method. Changing the method of a user clears the
+  credentials of its previous method.
+
+Set User Options:
+  -auth=<cert|userpass|ldap|okta|radius|approle|kubernetes|jwt|oidc|exec|token>
+    The auth method of the user. ldap, okta and radius users log in with a
+    username and password like userpass users.
+
+  -auth-path=<path>
+    The mount of the auth method of a cert, username, approle or exec user,
+    like corp-ldap for auth/corp-ldap. Defaults to the name of the method.
+
+  -client-certificate=<file>, -client-key=<file>
+    The client certificate and key files of a cert user.
+
+  -client-certificate-data=<pem>, -client-key-data=<pem>
+    The client certificate and key of a cert user.
+
+  -username=<username>, -password=<password>
+    The credentials of a userpass, ldap, okta or radius user.
+
+  -role-id=<role id>, -secret-id=<secret id>
+    The credentials of an approle user.
+
+  -kubernetes-role=<role>, -kubernetes-token-path=<file>
+    The role of a kubernetes user and its service account token, by default
+    the token kubernetes mounts in a pod.
+
+  -kubernetes-mount=<path>
+    The mount of the kubernetes auth method. Defaults to "kubernetes".
+
+  -jwt-role=<role>, -jwt-file=<file>, -jwt-env=<variable>
+    The role of a jwt user and its jwt, read from a file or an environment
+    variable like the id token of a CI job.
+
+  -jwt-mount=<path>
+    The mount of the jwt auth method. Defaults to "jwt".
+
+  -oidc-role=<role>, -oidc-mount=<path>
+    The role of an oidc user, by default the default role of the mount, and
+    the mount of the oidc auth method. Defaults to "oidc".
+
+  -oidc-listen-address=<host:port>
+    Where the browser is redirected to after logging in at the identity
+    provider. Defaults to "localhost:8250".
+
+  -oidc-skip-browser
+    Only print the url to log in at instead of opening the browser.
+
+  -token=<token>, -token-file=<file>, -token-env=<variable>
+    The token of a token user, given, read from a file or from an
+    environment variable like VAULT_TOKEN. The token is looked up when a
+    command loads the context, so that its session expires with it.
+
+  -token-helper
+    Use the token of the vault CLI: the token_helper of its config file or
+    ~/.vault-token, as written by vault login.
+
+  -exec-command=<command>
+    The credential plugin of an exec user. It prints a json credential on
+    stdout: {"token": "...", "ttl": 3600, "renewable": true} for a vault
+    token, {"role_id": "...", "secret_id": "..."} to log in with approle or
+    {"jwt": "...", "role": "..."} to log in with jwt. Without ttl it is run
+    for each command. It gets VAULTCLI_EXEC_SERVER, VAULTCLI_EXEC_NAMESPACE
+    and VAULTCLI_EXEC_USER in its environment.
+
+  -exec-arg=<arg>, -exec-env=<name>=<value>
+    An argument and an environment variable of the credential plugin, can
+    be repeated. Given, they replace the existing ones.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigSetUserCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
+		complete.Flags{
+			"-auth":                    complete.PredictSet("cert", "userpass", "ldap", "okta", "radius", "approle", "kubernetes", "jwt", "oidc", "exec", "token"),
+			"-client-certificate":      complete.PredictFiles("*"),
+			"-client-certificate-data": complete.PredictAnything,
+			"-client-key":              complete.PredictFiles("*"),
+			"-client-key-data":         complete.PredictAnything,
+			"-auth-path":               complete.PredictAnything,
+			"-username":                complete.PredictAnything,
+			"-password":                complete.PredictAnything,
+			"-role-id":                 complete.PredictAnything,
+			"-secret-id":               complete.PredictAnything,
+			"-kubernetes-role":         complete.PredictAnything,
+			"-kubernetes-token-path":   complete.PredictFiles("*"),
+			"-kubernetes-mount":        complete.PredictAnything,
+			"-jwt-role":                complete.PredictAnything,
+			"-jwt-file":                complete.PredictFiles("*"),
+			"-jwt-env":                 complete.PredictAnything,
+			"-jwt-mount":               complete.PredictAnything,
+			"-oidc-role":               complete.PredictAnything,
+			"-oidc-mount":              complete.PredictAnything,
+			"-oidc-listen-address":     complete.PredictAnything,
+			"-oidc-skip-browser":       complete.PredictNothing,
+			"-token":                   complete.PredictAnything,
+			"-token-file":              complete.PredictFiles("*"),
+			"-token-env":               complete.PredictAnything,
+			"-token-helper":            complete.PredictNothing,
+			"-exec-command":            complete.PredictFiles("*"),
+			"-exec-arg":                complete.PredictAnything,
+			"-exec-env":                complete.PredictAnything,
+		})
+}
+
+func (c *ConfigSetUserCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigSetUserCommand) Synopsis() string {
+	return "config set-user creates or updates a user and its credentials"
+}
+
+func (c *ConfigSetUserCommand) Name() string { return "config set-user" }
+
+// userAuthFlags are the credential flags of each auth method
+var userAuthFlags = map[string][]string{
+	"cert":       {"client-certificate", "client-certificate-data", "client-key", "client-key-data"},
+	"userpass":   {"username", "password"},
+	"approle":    {"role-id", "secret-id"},
+	"kubernetes": {"kubernetes-role", "kubernetes-token-path", "kubernetes-mount"},
+	"jwt":        {"jwt-role", "jwt-file", "jwt-env", "jwt-mount"},
+	"oidc":       {"oidc-role", "oidc-mount", "oidc-listen-address", "oidc-skip-browser"},
+	"exec":       {"exec-command", "exec-arg", "exec-env"},
+	"token":      {"token", "token-file", "token-env", "token-helper"},
+}
+
+func (c *ConfigSetUserCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.FlagAuth, "auth", "", "")
+	flagSet.StringVar(&c.FlagClientCert, "client-certificate", "", "")
+	flagSet.StringVar(&c.FlagClientCertData, "client-certificate-data", "", "")
+	flagSet.StringVar(&c.FlagClientKey, "client-key", "", "")
+	flagSet.StringVar(&c.FlagClientKeyData, "client-key-data", "", "")
+	flagSet.StringVar(&c.FlagAuthPath, "auth-path", "", "")
+	flagSet.StringVar(&c.FlagUsername, "username", "", "")
+	flagSet.StringVar(&c.FlagPassword, "password", "", "")
+	flagSet.StringVar(&c.FlagRoleID, "role-id", "", "")
+	flagSet.StringVar(&c.FlagSecretID, "secret-id", "", "")
+	flagSet.StringVar(&c.FlagK8sRole, "kubernetes-role", "", "")
+	flagSet.StringVar(&c.FlagK8sTokenPath, "kubernetes-token-path", "", "")
+	flagSet.StringVar(&c.FlagK8sMount, "kubernetes-mount", "", "")
+	flagSet.StringVar(&c.FlagJWTRole, "jwt-role", "", "")
+	flagSet.StringVar(&c.FlagJWTFile, "jwt-file", "", "")
+	flagSet.StringVar(&c.FlagJWTEnv, "jwt-env", "", "")
+	flagSet.StringVar(&c.FlagJWTMount, "jwt-mount", "", "")
+	flagSet.StringVar(&c.FlagOIDCRole, "oidc-role", "", "")
+	flagSet.StringVar(&c.FlagOIDCMount, "oidc-mount", "", "")
+	flagSet.StringVar(&c.FlagOIDCListen, "oidc-listen-address", "", "")
+	flagSet.BoolVar(&c.FlagOIDCNoBrowser, "oidc-skip-browser", false, "")
+	flagSet.StringVar(&c.FlagToken, "token", "", "")
+	flagSet.StringVar(&c.FlagTokenFile, "token-file", "", "")
+	flagSet.StringVar(&c.FlagTokenEnv, "token-env", "", "")
+	flagSet.BoolVar(&c.FlagTokenHelper, "token-helper", false, "")
+	flagSet.StringVar(&c.FlagExecCommand, "exec-command", "", "")
+	flagSet.Var((funcVar)(func(s string) error {
+		c.FlagExecArgs = append(c.FlagExecArgs, s)
+		return nil
+	}), "exec-arg", "")
+	flagSet.Var((funcVar)(func(s string) error {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("expected -exec-env=<name>=<value>, got %q", s)
+		}
+		c.FlagExecEnv = append(c.FlagExecEnv, config.ExecEnvVar{Name: parts[0], Value: parts[1]})
+		return nil
+	}), "exec-env", "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <name>")
+		return 1
+	}
+	name := args[0]
+	auth, err := c.authMethod(flagSet)
+	if err != nil {
+		c.Meta.Ui.Error(err.Error())
+		return 1
+	}
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	if found := c.Meta.Config.GetUserByName(name); found != nil {
+		// credential options keep the method of their kind, like ldap
+		// for -password
+		if current := userAuthMethod(found); auth == "" || c.FlagAuth == "" && authKind(current) == auth {
+			auth = current
+		}
+		if auth != "" {
+			clearOtherCredentials(found, auth)
+		}
+	}
+	if auth == "" {
+		c.Meta.Ui.Error("give the auth method of the user with -auth or credential options")
+		return 1
+	}
+
+	switch auth {
+	case "cert":
+		_, err = c.Meta.Config.SetCertUser(name, c.FlagClientCert, c.FlagClientCertData, c.FlagClientKey, c.FlagClientKeyData)
+	case "userpass", "ldap", "okta", "radius":
+		_, err = c.Meta.Config.SetUserPassUser(name, c.FlagUsername, c.FlagPassword)
+	case "approle":
+		_, err = c.Meta.Config.SetAppRoleUser(name, c.FlagRoleID, c.FlagSecretID)
+	case "kubernetes":
+		_, err = c.Meta.Config.SetKubernetesUser(name, c.FlagK8sRole, c.FlagK8sTokenPath, c.FlagK8sMount)
+	case "jwt":
+		_, err = c.Meta.Config.SetJWTUser(name, c.FlagJWTRole, c.FlagJWTFile, c.FlagJWTEnv, c.FlagJWTMount)
+	case "oidc":
+		var skipBrowser *bool
+		if flagGiven(flagSet, "oidc-skip-browser") {
+			skipBrowser = &c.FlagOIDCNoBrowser
+		}
+		_, err = c.Meta.Config.SetOIDCUser(name, c.FlagOIDCRole, c.FlagOIDCMount, c.FlagOIDCListen, skipBrowser)
+	case "exec":
+		_, err = c.Meta.Config.SetExecUser(name, c.FlagExecCommand, c.FlagExecArgs, c.FlagExecEnv)
+	case "token":
+		_, err = c.Meta.Config.SetTokenUser(name, c.FlagToken, c.FlagTokenFile, c.FlagTokenEnv, c.FlagTokenHelper)
+	}
+	if err == nil {
+		_, err = c.Meta.Config.SetUserAuthMethod(name, auth, c.FlagAuthPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	// credentials of a migrated user go to the credential store
+	_, err = c.Meta.Config.StoreSecrets("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("User %q set", name))
+	return 0
+}
+
+// authMethod returns the auth method given with -auth or by the credential
+// flags, "" when neither is given
+func (c *ConfigSetUserCommand) authMethod(flagSet *flag.FlagSet) (string, error) {
+	methods := []string{}
+	for _, method := range []string{"cert", "userpass", "approle", "kubernetes", "jwt", "oidc", "exec", "token"} {
+		for _, name := range userAuthFlags[method] {
+			if flagGiven(flagSet, name) {
+				methods = append(methods, method)
+				break
+			}
+		}
+	}
+	if len(methods) > 1 {
+		return "", fmt.Errorf("credential options of %s cannot be combined", strings.Join(methods, " and "))
+	}
+	switch {
+	case c.FlagAuth == "" && len(methods) == 1:
+		return methods[0], nil
+	case c.FlagAuth == "":
+		return "", nil
+	case userAuthFlags[authKind(c.FlagAuth)] == nil:
+		return "", fmt.Errorf("unknown auth method %s, expected cert, userpass, ldap, okta, radius, approle, kubernetes, jwt, oidc, exec or token", c.FlagAuth)
+	case len(methods) == 1 && methods[0] != authKind(c.FlagAuth):
+		return "", fmt.Errorf("credential options of %s cannot be used with -auth=%s", methods[0], c.FlagAuth)
+	}
+	return c.FlagAuth, nil
+}
+
+// userAuthMethod returns the auth method of user the way GetSession picks
+// it, "" when the user has no credentials
+func userAuthMethod(user *config.User) string {
+	switch {
+	case user.IsTokenUser():
+		return "token"
+	case user.Exec != nil:
+		return "exec"
+	case user.Kubernetes != nil:
+		return "kubernetes"
+	case user.JWT != nil:
+		return "jwt"
+	case user.OIDC != nil:
+		return "oidc"
+	case user.ClientCert != "" || user.ClientCertData != "":
+		return "cert"
+	case user.Username != "" && user.AuthMethod != "":
+		return user.AuthMethod
+	case user.Username != "":
+		return "userpass"
+	case user.RoleID != "":
+		return "approle"
+	}
+	return ""
+}
+
+// clearOtherCredentials clears the credentials user has for auth methods
+// other than auth, so that GetSession logs in with auth
+func clearOtherCredentials(user *config.User, auth string) {
+	if auth != userAuthMethod(user) {
+		user.AuthMethod, user.AuthPath = "", ""
+	}
+	if auth != "cert" {
+		user.ClientCert, user.ClientCertData, user.ClientKey, user.ClientKeyData = "", "", "", ""
+	}
+	if authKind(auth) != "userpass" {
+		user.Username, user.Password, user.PasswordRef = "", "", ""
+	}
+	if auth != "approle" {
+		user.RoleID, user.SecretID, user.SecretIDRef = "", "", ""
+	}
+	if auth != "exec" {
+		user.Exec = nil
+	}
+	if auth != "kubernetes" {
+		user.Kubernetes = nil
+	}
+	if auth != "jwt" {
+		user.JWT = nil
+	}
+	if auth != "oidc" {
+		user.OIDC = nil
+	}
+	if auth != "token" {
+		user.Token, user.TokenRef, user.TokenFile, user.TokenEnv, user.TokenHelper = "", "", "", "", false
+	}
+}
+
+// authKind returns the auth method whose credential options auth takes,
+// userpass for the username and password methods
+func authKind(auth string) string {
+	if config.IsPasswordAuthMethod(auth) {
+		return "userpass"
+	}
+	return auth
+}
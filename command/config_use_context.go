@@ -0,0 +1,78 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type ConfigUseContextCommand struct {
+	Meta Meta
+}
+
+func (c *ConfigUseContextCommand) Help() string {
+	helpText := `
+Usage: vault-cli config use-context [options] <name>
+
+  Sets the current context of the config file.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigUseContextCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *ConfigUseContextCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictAnything
+}
+
+func (c *ConfigUseContextCommand) Synopsis() string {
+	return "config use-context sets the current context"
+}
+
+func (c *ConfigUseContextCommand) Name() string { return "config use-context" }
+
+func (c *ConfigUseContextCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <name>")
+		return 1
+	}
+	name := args[0]
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	if c.Meta.Config.GetContextByName(name) == nil {
+		fmt.Fprintf(os.Stderr, "context %q not found, available contexts: %s\n", name, strings.Join(c.Meta.contextNames(), ", "))
+		return 1
+	}
+	c.Meta.Config.CurrentContext = name
+	err = c.Meta.saveConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write config: %s\n", err)
+		return 1
+	}
+	c.Meta.Ui.Output(fmt.Sprintf("Switched to context %q", name))
+	return 0
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/posener/complete"
+)
+
+type ConfigViewCommand struct {
+	Meta        Meta
+	FlagRaw     bool
+	FlagFlatten bool
+}
+
+func (c *ConfigViewCommand) Help() string {
+	helpText := `
+Usage: vault-cli config view [options]
+
+  Prints the config file. Session tokens, passwords, secret ids and client
+  key data are redacted. When the config is a list of files each file is
+  printed, see -flatten.
+
+View Options:
+  -raw
+    Print secrets as they are stored.
+
+  -flatten
+    Print the config files merged into one config, the way commands see it.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigViewCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
+		complete.Flags{
+			"-raw":     complete.PredictNothing,
+			"-flatten": complete.PredictNothing,
+		})
+}
+
+func (c *ConfigViewCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigViewCommand) Synopsis() string {
+	return "config view prints the config file"
+}
+
+func (c *ConfigViewCommand) Name() string { return "config view" }
+
+func (c *ConfigViewCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagRaw, "raw", false, "")
+	flagSet.BoolVar(&c.FlagFlatten, "flatten", false, "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	configPath, err := c.Meta.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+
+	files := filepath.SplitList(configPath)
+	if c.FlagFlatten || len(files) == 1 {
+		err = c.view(c.Meta.Config)
+	} else {
+		err = c.viewFiles(files)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// configFileView is a config file of a list of config files
+type configFileView struct {
+	File   string         `json:"file"`
+	Config *config.Config `json:"config"`
+}
+
+// view prints cfg, redacted unless -raw
+func (c *ConfigViewCommand) view(cfg *config.Config) error {
+	cfg, err := c.redact(cfg)
+	if err != nil {
+		return err
+	}
+	return c.Meta.output(cfg, func(w io.Writer) error {
+		_, err := fmt.Fprint(w, cfg.String())
+		return err
+	})
+}
+
+// viewFiles prints each of the config files that exists
+func (c *ConfigViewCommand) viewFiles(files []string) error {
+	views := []configFileView{}
+	for _, f := range files {
+		if _, err := os.Stat(f); os.IsNotExist(err) {
+			continue
+		}
+		cfg, err := c.Meta.ConfigService.Read(f)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %s", f, err)
+		}
+		cfg, err = c.redact(cfg)
+		if err != nil {
+			return err
+		}
+		views = append(views, configFileView{File: f, Config: cfg})
+	}
+	return c.Meta.output(views, func(w io.Writer) error {
+		for i, v := range views {
+			if i > 0 {
+				fmt.Fprintln(w, "---")
+			}
+			_, err := fmt.Fprintf(w, "# %s\n%s", v.File, v.Config.String())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// redact returns cfg with its secrets redacted unless -raw
+func (c *ConfigViewCommand) redact(cfg *config.Config) (*config.Config, error) {
+	if c.FlagRaw {
+		return cfg, nil
+	}
+	redacted, err := cfg.Redacted()
+	if err != nil {
+		return nil, fmt.Errorf("unable to redact config: %s", err)
+	}
+	return redacted, nil
+}
@@ -0,0 +1,392 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	pkgargs "github.com/ibm/vault-cli/pkg/args"
+	"github.com/ibm/vault-cli/pkg/events"
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+type DeleteCommand struct {
+	Meta Meta
+}
+
+func (f *DeleteCommand) Help() string {
+	helpText := `
+Usage: vault-cli delete <kind> [options] <filespec>
+
+  Deletes the vault objects declared by the inventory files of a kind that
+  match filespec. Kinds are vaultnamespace, vaultendpoint, vaultauth,
+  vaultpolicy, vaultrole, jwtrole, pkirole, sshrole and secret.
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (f *DeleteCommand) Synopsis() string {
+	return "delete removes the vault objects of inventory files"
+}
+
+func (f *DeleteCommand) Name() string { return "delete" }
+
+func (f *DeleteCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// DeleteKindCommand deletes the vault objects declared by the inventory
+// files of one kind
+type DeleteKindCommand struct {
+	Meta        Meta
+	Kind        string
+	FlagConfirm bool
+}
+
+// deleteKind renders an inventory file into the delete of the object it
+// declares. Destructive kinds take everything below the object with it
+// and are only deleted once confirmed.
+type deleteKind struct {
+	dir         string
+	destructive bool
+	target      func(m *Meta, f string) (*plan.Change, error)
+}
+
+// deleteKinds are the kinds delete removes, by the name of the subcommand
+var deleteKinds = map[string]deleteKind{
+	"vaultnamespace": {dir: "vaultnamespace", destructive: true, target: namespaceDelete},
+	"vaultendpoint":  {dir: "vaultendpoint", destructive: true, target: endpointDelete},
+	"vaultauth":      {dir: "vaultauth", destructive: true, target: authDelete},
+	"vaultpolicy": {dir: "vaultpolicy", target: func(m *Meta, f string) (*plan.Change, error) {
+		return (&PutVaultPolicyCommand{Meta: *m}).change(f)
+	}},
+	"vaultrole": {dir: "vaultrole", target: func(m *Meta, f string) (*plan.Change, error) {
+		return (&PutVaultRoleCommand{Meta: *m}).change(f)
+	}},
+	"jwtrole": {dir: "jwtrole", target: func(m *Meta, f string) (*plan.Change, error) {
+		return (&PutJWTRoleCommand{Meta: *m}).change(f)
+	}},
+	"pkirole": {dir: "pkirole", target: func(m *Meta, f string) (*plan.Change, error) {
+		return (&PutPKIRoleCommand{Meta: *m}).change(f)
+	}},
+	"sshrole": {dir: "sshrole", target: func(m *Meta, f string) (*plan.Change, error) {
+		return (&PutSSHRoleCommand{Meta: *m}).change(f)
+	}},
+	"secret": {dir: "secretmeta", destructive: true, target: secretDelete},
+}
+
+func (c *DeleteKindCommand) Help() string {
+	helpText := `
+Usage: vault-cli delete ` + c.Kind + ` [options] <filespec>
+
+  Renders the ` + deleteKinds[c.Kind].dir + ` inventory files matching filespec and
+  deletes the vault objects they declare. Objects put from another context
+  or inventory file are refused. Namespaces, mounts, auth methods and
+  secrets take everything below them with them: their deletes are listed
+  and must be confirmed, with -confirm or by answering yes.
+
+Delete Options:
+  -confirm
+    Delete without asking for confirmation.
+
+  ` + eventOptionsUsage() + `
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DeleteKindCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-confirm": complete.PredictNothing,
+		})
+}
+
+func (c *DeleteKindCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *DeleteKindCommand) Synopsis() string {
+	return "delete " + c.Kind + " removes the vault objects of " + deleteKinds[c.Kind].dir + " inventory files"
+}
+
+func (c *DeleteKindCommand) Name() string { return "delete " + c.Kind }
+
+// deleteResult is what delete did with the object of one inventory file
+type deleteResult struct {
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// deleteReport is the result delete prints. Objects are deleted, failed or
+// planned when the delete was cancelled or stopped at a failure.
+type deleteReport struct {
+	Objects   []deleteResult `json:"objects"`
+	Deleted   int            `json:"deleted"`
+	Failed    int            `json:"failed"`
+	Cancelled bool           `json:"cancelled,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+func (c *DeleteKindCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagConfirm, "confirm", false, "")
+	c.Meta.eventFlags(flagSet)
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 1 {
+		c.Meta.Ui.Error("This command takes one argument: <filespec>")
+		return 1
+	}
+	filespec := args[0]
+	kind := deleteKinds[c.Kind]
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+	defer c.Meta.closeEvents()
+
+	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/"+kind.dir+"/", filespec)
+	if err != nil {
+		fmt.Printf("get files error: %s\n", err.Error())
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Printf("%s (%s) not found in inventory\n", kind.dir, filespec)
+		return 1
+	}
+
+	report := c.delete(kind, files)
+	err = c.Meta.output(report, func(w io.Writer) error {
+		switch {
+		case report.Error != "":
+			fmt.Fprintf(w, "%s\n", report.Error)
+		case report.Cancelled:
+			fmt.Fprintf(w, "Delete cancelled\n")
+		default:
+			fmt.Fprintf(w, "Delete complete: %d deleted, %d failed\n", report.Deleted, report.Failed)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if report.Failed > 0 || report.Cancelled || report.Error != "" {
+		return 1
+	}
+	return 0
+}
+
+// delete renders the files into deletes, asks for confirmation of
+// destructive kinds unless -confirm is set and deletes the objects in
+// order, stopping at the first failure
+func (c *DeleteKindCommand) delete(kind deleteKind, files []string) *deleteReport {
+	report := &deleteReport{Objects: []deleteResult{}}
+	defaultNamespace := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	deletes := []*plan.Change{}
+	for _, f := range files {
+		c.Meta.SecretService.GetClient().SetNamespace(defaultNamespace)
+		d, err := kind.target(&c.Meta, f)
+		if err == nil {
+			err = c.Meta.claim(kind.dir, f, d.Namespace, d.Path)
+		}
+		if err == nil {
+			err = c.claimMarker(kind.dir, f, d)
+		}
+		if err != nil {
+			report.Error = fmt.Sprintf("%s: (%s) %s", kind.dir, f, err)
+			return report
+		}
+		del := plan.NewDelete(kind.dir, d.Namespace, d.Path)
+		del.File = f
+		deletes = append(deletes, del)
+	}
+	if kind.dir == "vaultnamespace" {
+		// child namespaces must go before the namespace they are based on
+		depth := func(d *plan.Change) int {
+			return namespaceDepth(d.Namespace, strings.TrimPrefix(d.Path, "/sys/namespaces/"))
+		}
+		sort.SliceStable(deletes, func(i, j int) bool {
+			return depth(deletes[i]) > depth(deletes[j])
+		})
+	}
+
+	if kind.destructive && !c.FlagConfirm {
+		if c.Meta.printer.Structured() {
+			report.Error = fmt.Sprintf("delete cannot ask for confirmation with -output %s, use -confirm", c.Meta.outputFormat)
+			return report
+		}
+		printChanges(c.Meta.infoWriter(), deletes)
+		answer, err := c.Meta.Ui.Ask(fmt.Sprintf("Delete %d objects and everything below them? Only 'yes' will be accepted:", len(deletes)))
+		if err != nil || answer != "yes" {
+			report.Cancelled = true
+			for _, d := range deletes {
+				report.Objects = append(report.Objects, deleteResult{Kind: d.Kind, File: d.File, Namespace: export.Namespace(d.Namespace), Path: d.Path, Status: "planned"})
+			}
+			return report
+		}
+	}
+
+	for i, d := range deletes {
+		result := deleteResult{Kind: d.Kind, File: d.File, Namespace: export.Namespace(d.Namespace), Path: d.Path, Status: "deleted"}
+		c.Meta.SecretService.GetClient().SetNamespace(d.Namespace)
+		start := time.Now()
+		_, err := c.Meta.SecretService.Delete(d.Path)
+		e := &events.Event{
+			Time:      start.UTC(),
+			Kind:      d.Kind,
+			File:      d.File,
+			Namespace: result.Namespace,
+			Path:      d.Path,
+			Action:    events.ActionDeleted,
+			Duration:  time.Since(start).Seconds(),
+		}
+		if err != nil {
+			e.Action, e.Error = events.ActionFailed, err.Error()
+		}
+		c.Meta.record(e)
+		if err != nil {
+			report.Failed++
+			result.Status, result.Error = "failed", err.Error()
+			report.Objects = append(report.Objects, result)
+			c.Meta.infof("%s: (%s) %s %s\n", d.Kind, d.File, d.Path, err)
+			for _, rest := range deletes[i+1:] {
+				report.Objects = append(report.Objects, deleteResult{Kind: rest.Kind, File: rest.File, Namespace: export.Namespace(rest.Namespace), Path: rest.Path, Status: "planned"})
+			}
+			break
+		}
+		report.Deleted++
+		report.Objects = append(report.Objects, result)
+		if c.Meta.Owners != nil {
+			if err := c.Meta.Owners.Delete(d.Namespace, d.Path); err != nil {
+				c.Meta.infof("%s: %s unable to remove from ownership index: %s\n", d.Kind, d.Path, err)
+			}
+		}
+	}
+	return report
+}
+
+// claimMarker returns an error when the mount, auth method or secret d
+// deletes carries the ownership marker of another context or inventory
+// file, as put refuses to take them over
+func (c *DeleteKindCommand) claimMarker(kind, f string, d *plan.Change) error {
+	c.Meta.SecretService.GetClient().SetNamespace(d.Namespace)
+	switch {
+	case strings.HasPrefix(d.Path, "sys/mounts/"):
+		current, err := c.Meta.SecretService.Read(d.Path + "/tune")
+		if err == nil && current != nil {
+			description, _ := current.Data["description"].(string)
+			return c.Meta.claimMarker(kind, f, d.Path, description)
+		}
+	case strings.HasPrefix(d.Path, "sys/auth/"):
+		secret, err := c.Meta.SecretService.Read("sys/auth")
+		if err == nil && secret != nil {
+			if current, ok := secret.Data[strings.TrimPrefix(d.Path, "sys/auth/")+"/"].(map[string]interface{}); ok {
+				description, _ := current["description"].(string)
+				return c.Meta.claimMarker(kind, f, d.Path, description)
+			}
+		}
+	case kind == "secretmeta":
+		secret, err := c.Meta.SecretService.Read(d.Path)
+		if err == nil && secret != nil {
+			metadata, _ := secret.Data["custom_metadata"].(map[string]interface{})
+			ctx, _ := metadata["vault-cli-context"].(string)
+			file, _ := metadata["vault-cli-file"].(string)
+			if ctx != "" && (ctx != c.Meta.CurrentContext.Name || file != kind+"/"+f) {
+				return fmt.Errorf("%s is managed by %s in context %s", d.Path, file, ctx)
+			}
+		}
+	}
+	return nil
+}
+
+// namespaceDelete returns the delete of the namespace declared by the
+// vaultnamespace inventory file f, in its base namespace
+func namespaceDelete(m *Meta, f string) (*plan.Change, error) {
+	ns, err := (&PutVaultNamespaceCommand{Meta: *m}).Render(f)
+	if err != nil {
+		return nil, err
+	}
+	base := ns.Spec.NamespaceBase
+	if base == "" {
+		base = m.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	}
+	return plan.NewDelete("vaultnamespace", base, "/sys/namespaces/"+ns.Spec.NamespaceName), nil
+}
+
+// endpointDelete returns the unmount of the secret engine declared by the
+// vaultendpoint inventory file f
+func endpointDelete(m *Meta, f string) (*plan.Change, error) {
+	endpoint := vaultapi.VaultEndpoint{}
+	err := m.renderInventoryFile("vaultendpoint", f, "VaultEndpoint", &endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewDelete("vaultendpoint", endpoint.Spec.VaultNamespace, "sys/mounts/"+endpoint.Spec.Path), nil
+}
+
+// authDelete returns the disable of the auth method declared by the
+// vaultauth inventory file f
+func authDelete(m *Meta, f string) (*plan.Change, error) {
+	auth := vaultapi.VaultAuth{}
+	err := m.renderInventoryFile("vaultauth", f, "VaultAuth", &auth)
+	if err != nil {
+		return nil, err
+	}
+	ns := auth.Spec.VaultNamespace
+	if ns == "" {
+		ns = m.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	}
+	return plan.NewDelete("vaultauth", ns, "sys/auth/"+auth.Spec.Path), nil
+}
+
+// secretDelete returns the delete of the kv-v2 metadata, and with it every
+// version, of the secret declared by the secretmeta inventory file f
+func secretDelete(m *Meta, f string) (*plan.Change, error) {
+	secretmeta := vaultapi.SecretMeta{}
+	err := m.renderInventoryFile("secretmeta", f, "Secret", &secretmeta)
+	if err != nil {
+		return nil, err
+	}
+	if secretmeta.Spec.Type != "kv-v2" {
+		return nil, fmt.Errorf("secret type must be kv-v2")
+	}
+	path := secretmeta.Spec.KVPath.Path
+	mountPath, v2, err := m.SecretService.IsKVv2(path)
+	if err != nil {
+		return nil, fmt.Errorf("error:%s", err.Error())
+	}
+	if !v2 {
+		return nil, fmt.Errorf("%s is not a kv-v2 path", path)
+	}
+	ns := m.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	return plan.NewDelete("secretmeta", ns, pkgargs.AddPrefixToVKVPath(path, mountPath, "metadata")), nil
+}
@@ -0,0 +1,75 @@
+package command_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteRefusesMarkedMount(t *testing.T) {
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.URL.Path == "/v1/sys/mounts/demo/tune":
+			w.Write([]byte(`{"data":{"description":"demo [vault-cli context=prod file=vaultendpoint/demo]"}}`))
+		case r.Method == http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"vaultendpoint/demo.yaml": putEndpoint})
+	defer os.RemoveAll(dir)
+
+	code, lines, _ := runCommand(t, "delete vaultendpoint", "-config", configPath, "-confirm", "-o", "json", "demo")
+	if code != 1 {
+		t.Fatalf("expected the delete of a mount of context prod to be refused, got %d", code)
+	}
+	if deleted {
+		t.Errorf("expected no delete of a mount of context prod")
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "managed by vaultendpoint/demo in context prod") {
+		t.Errorf("expected the owner in the error, got %q", lines)
+	}
+}
+
+func TestDeleteEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"vaultpolicy/operator.yaml": applyPolicy})
+	defer os.RemoveAll(dir)
+	eventsPath := filepath.Join(dir, "events.json")
+
+	code, _, errors := runCommand(t, "delete vaultpolicy", "-config", configPath, "-events", eventsPath, "operator")
+	if code != 0 {
+		t.Fatalf("delete failed with %d: %s", code, errors)
+	}
+	events, err := ioutil.ReadFile(eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(events), `"action":"deleted"`) {
+		t.Errorf("expected a deleted event, got %q", events)
+	}
+}
@@ -0,0 +1,225 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/owner"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+	"github.com/posener/complete"
+	"gopkg.in/yaml.v2"
+)
+
+type DescribeCommand struct {
+	Meta Meta
+}
+
+func (c *DescribeCommand) Help() string {
+	helpText := `
+Usage: vault-cli describe [options] <kind> <name>
+
+  Reads a live object from vault, in the context namespace or -namespace,
+  and prints its spec with the vault path it lives at, the context and
+  inventory file that manage it, and the roles of auth methods and pki or
+  ssh engines or the child namespaces of a namespace.
+
+  Kinds and names are those of get, see vault-cli get -help. The managing
+  context comes from the ownership index of the context, the description
+  marker of mounts and auth methods or the custom metadata of secrets.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DescribeCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *DescribeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet(liveKindNames()...)
+}
+
+func (c *DescribeCommand) Synopsis() string {
+	return "describe shows a live vault object with its owner and related objects"
+}
+
+func (c *DescribeCommand) Name() string { return "describe" }
+
+// objectDescription is what describe prints for a live object
+type objectDescription struct {
+	Kind       string       `json:"kind"`
+	Name       string       `json:"name"`
+	Namespace  string       `json:"namespace"`
+	Path       string       `json:"path"`
+	ManagedBy  *objectOwner `json:"managedBy,omitempty"`
+	Roles      []string     `json:"roles,omitempty"`
+	Namespaces []string     `json:"namespaces,omitempty"`
+	Spec       interface{}  `json:"spec"`
+}
+
+// objectOwner is the context and inventory file that last put an object.
+// Applied is unknown for owners read from a description marker.
+type objectOwner struct {
+	Context string `json:"context"`
+	File    string `json:"file"`
+	Applied string `json:"applied,omitempty"`
+}
+
+func (c *DescribeCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) != 2 {
+		c.Meta.Ui.Error("This command takes two arguments: <kind> <name>")
+		return 1
+	}
+	lk, ok := liveKinds[strings.ToLower(args[0])]
+	if !ok {
+		c.Meta.Ui.Error(fmt.Sprintf("unknown kind %s, expected one of %s", args[0], strings.Join(liveKindNames(), ", ")))
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+
+	r := export.NewReader(c.Meta.SecretService)
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	o, err := lk.read(r, ns, args[1])
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	d, err := c.describe(r, o)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	err = c.Meta.output(d, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+		fmt.Fprintf(tw, "Kind:\t%s\n", d.Kind)
+		fmt.Fprintf(tw, "Name:\t%s\n", d.Name)
+		fmt.Fprintf(tw, "Namespace:\t%s\n", d.Namespace)
+		fmt.Fprintf(tw, "Path:\t%s\n", d.Path)
+		if d.ManagedBy == nil {
+			fmt.Fprintf(tw, "Managed by:\tunmanaged\n")
+		} else if d.ManagedBy.Applied == "" {
+			fmt.Fprintf(tw, "Managed by:\t%s in context %s\n", d.ManagedBy.File, d.ManagedBy.Context)
+		} else {
+			fmt.Fprintf(tw, "Managed by:\t%s in context %s, applied %s\n", d.ManagedBy.File, d.ManagedBy.Context, d.ManagedBy.Applied)
+		}
+		if o.kind == "VaultAuth" || d.Roles != nil {
+			fmt.Fprintf(tw, "Roles:\t%s\n", strings.Join(d.Roles, ", "))
+		}
+		if o.kind == "VaultNamespace" {
+			fmt.Fprintf(tw, "Namespaces:\t%s\n", strings.Join(d.Namespaces, ", "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		spec, err := yaml.Marshal(d.Spec)
+		if err != nil {
+			return fmt.Errorf("error marshaling spec: %s", err)
+		}
+		fmt.Fprintf(w, "Spec:\n")
+		for _, line := range strings.Split(strings.TrimRight(string(spec), "\n"), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// describe returns the description of o, reading its owner and related
+// objects
+func (c *DescribeCommand) describe(r *export.Reader, o *liveObject) (*objectDescription, error) {
+	d := &objectDescription{
+		Kind:      o.kind,
+		Name:      o.name,
+		Namespace: export.Namespace(o.namespace),
+		Path:      o.path,
+		Spec:      o.spec,
+	}
+	managedBy, err := c.managedBy(r, o)
+	if err != nil {
+		return nil, err
+	}
+	d.ManagedBy = managedBy
+	export.StripMarkers(o.spec)
+
+	switch spec := o.spec.(type) {
+	case *vaultapi.VaultAuthSpec:
+		d.Roles, err = r.AuthRoles(o.namespace, spec.Path)
+	case *vaultapi.VaultEndpointSpec:
+		if spec.MountOptions.Type == "pki" || spec.MountOptions.Type == "ssh" {
+			d.Roles, err = r.EngineRoles(o.namespace, spec.Path)
+		}
+	case *vaultapi.VaultNamespaceSpec:
+		d.Namespaces, err = r.Namespaces(strings.Trim(o.namespace+"/"+spec.NamespaceName, "/"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// managedBy returns the owner of o from the ownership index, else from the
+// description marker of mounts and auth methods or the custom metadata of
+// secrets. It returns nil for objects vault-cli did not put.
+func (c *DescribeCommand) managedBy(r *export.Reader, o *liveObject) (*objectOwner, error) {
+	if c.Meta.Owners != nil && o.kind != "SecretMeta" {
+		rec, err := c.Meta.Owners.Get(o.namespace, o.path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ownership index: %s", err)
+		}
+		if rec != nil {
+			return &objectOwner{Context: rec.Context, File: rec.Kind + "/" + rec.File, Applied: rec.Applied.Format(time.RFC3339)}, nil
+		}
+	}
+
+	description := ""
+	switch spec := o.spec.(type) {
+	case *vaultapi.VaultAuthSpec:
+		description = spec.Data.Description
+	case *vaultapi.VaultEndpointSpec:
+		description = spec.TuneOptions.Description
+		if description == "" {
+			description = spec.MountOptions.Description
+		}
+	case *vaultapi.SecretMetaSpec:
+		_, metadata, err := r.Secret(o.namespace, o.name)
+		if err != nil {
+			return nil, err
+		}
+		if metadata["vault-cli-context"] != "" {
+			return &objectOwner{Context: metadata["vault-cli-context"], File: metadata["vault-cli-file"], Applied: metadata["vault-cli-applied"]}, nil
+		}
+	}
+	if ctx, file, ok := owner.Parse(description); ok {
+		return &objectOwner{Context: ctx, File: file}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,179 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/events"
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/plan"
+	"github.com/posener/complete"
+)
+
+// eventFlags adds the -events and -junit flags of the commands that put
+// inventory files
+func (m *Meta) eventFlags(f *flag.FlagSet) {
+	f.StringVar(&m.eventsPath, "events", "", "")
+	f.StringVar(&m.junitPath, "junit", "", "")
+}
+
+// eventAutocompleteFlags returns the completions of eventFlags
+func eventAutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-events": complete.PredictFiles("*"),
+		"-junit":  complete.PredictFiles("*.xml"),
+	}
+}
+
+// eventOptionsUsage returns the help string for eventFlags
+func eventOptionsUsage() string {
+	helpText := `
+  -events=<file>
+    Write an event per inventory file or deleted object as a line of json
+    to file, or to stdout when file is "-", which moves progress and the
+    text summary to stderr: time, kind, file, namespace, path, action
+    (created, updated, unchanged, deleted, failed or applied when the prior
+    state could not be read), error and duration in seconds.
+
+  -junit=<file>
+    Write a junit xml report to file with a test case per inventory file or
+    deleted object, grouped in a test suite per kind.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// openEvents starts recording events when -events or -junit is set
+func (m *Meta) openEvents() error {
+	if m.eventsPath == "" && m.junitPath == "" {
+		return nil
+	}
+	var w io.Writer
+	switch m.eventsPath {
+	case "":
+	case "-":
+		w = os.Stdout
+	default:
+		file, err := os.Create(m.eventsPath)
+		if err != nil {
+			return fmt.Errorf("unable to create events file: %s", err)
+		}
+		m.eventsFile = file
+		w = file
+	}
+	m.events = events.NewRecorder(w)
+	return nil
+}
+
+// closeEvents closes the events file and writes the junit report. Errors
+// are printed, the result of the command is not changed.
+func (m *Meta) closeEvents() {
+	if m.eventsFile != nil {
+		if err := m.eventsFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to write events file: %s\n", err)
+		}
+		m.eventsFile = nil
+	}
+	if m.junitPath == "" || m.events == nil {
+		return
+	}
+	file, err := os.Create(m.junitPath)
+	if err == nil {
+		name := "vault-cli"
+		if m.CurrentContext != nil {
+			name = "vault-cli " + m.CurrentContext.Name
+		}
+		err = m.events.WriteJUnit(file, name)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write junit report: %s\n", err)
+	}
+}
+
+// record adds e to the events of the run. Successful puts are printed as
+// progress, failures are printed by the caller.
+func (m *Meta) record(e *events.Event) {
+	switch {
+	case e.Action == events.ActionFailed:
+	case e.File == "":
+		m.infof("%s: %s %s\n", e.Kind, e.Path, e.Action)
+	default:
+		m.infof("%s: (%s) %s %s\n", e.Kind, e.File, e.Path, e.Action)
+	}
+	if m.events == nil {
+		return
+	}
+	if err := m.events.Record(e); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write event: %s\n", err)
+	}
+}
+
+// putFile puts the inventory file f of kind and records and returns what
+// the put did. The file is planned first to tell created, updated and
+// unchanged objects apart; when the plan fails the put still runs and is
+// recorded as applied.
+func (m *Meta) putFile(kind, f string, put func(f string) error, planFn func(f string) ([]*plan.Change, error)) (events.Action, error) {
+	start := time.Now()
+	e := &events.Event{Time: start.UTC(), Kind: kind, File: f, Action: events.ActionApplied}
+
+	client := m.SecretService.GetClient()
+	ns := client.Headers().Get("X-Vault-Namespace")
+	if changes, err := planFn(f); err == nil {
+		e.Action = putAction(changes)
+		if len(changes) > 0 {
+			e.Namespace = export.Namespace(changes[0].Namespace)
+			e.Path = changes[0].Path
+		}
+	}
+	client.SetNamespace(ns)
+
+	err := put(f)
+	e.Duration = time.Since(start).Seconds()
+	if err != nil {
+		e.Action = events.ActionFailed
+		e.Error = err.Error()
+	}
+	m.record(e)
+	m.puts = append(m.puts, putResult{
+		Kind:      kind,
+		File:      f,
+		Namespace: e.Namespace,
+		Path:      e.Path,
+		Action:    string(e.Action),
+		Error:     e.Error,
+	})
+	return e.Action, err
+}
+
+// putAction returns the action a put of the planned changes takes
+func putAction(changes []*plan.Change) events.Action {
+	action := events.ActionUnchanged
+	for _, change := range changes {
+		switch change.Action {
+		case plan.ActionCreate:
+			return events.ActionCreated
+		case plan.ActionUpdate, plan.ActionDelete:
+			action = events.ActionUpdated
+		}
+	}
+	return action
+}
+
+// changeAction returns the action of a saved plan change once written
+func changeAction(change *plan.Change) events.Action {
+	switch change.Action {
+	case plan.ActionCreate:
+		return events.ActionCreated
+	case plan.ActionUpdate:
+		return events.ActionUpdated
+	case plan.ActionDelete:
+		return events.ActionDeleted
+	}
+	return events.ActionUnchanged
+}
@@ -0,0 +1,297 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/posener/complete"
+)
+
+type ExportCommand struct {
+	Meta      Meta
+	FlagForce bool
+	ioDir     string
+	reader    *export.Reader
+	report    exportReport
+}
+
+// exportResult is the outcome of exporting one inventory file. File is empty
+// when the object could not be read.
+type exportResult struct {
+	Kind   string `json:"kind"`
+	File   string `json:"file,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// exportReport is the result export prints
+type exportReport struct {
+	Files   []exportResult `json:"files"`
+	Written int            `json:"written"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+}
+
+func (c *ExportCommand) Help() string {
+	helpText := `
+Usage: vault-cli export [options] -dir=<directory>
+
+  Reads the live configuration of vault, starting at the context namespace
+  and walking every child namespace, and writes it as inventory yaml in the
+  layout put reads: vaultnamespace, vaultendpoint, vaultauth, vaultpolicy,
+  vaultrole, jwtrole, pkirole and sshrole directories holding one file per
+  object. Policies are parsed back into paths and capabilities.
+
+  Secrets, pki CA material and the builtin sys/, identity/, cubbyhole/ and
+  token/ mounts, root and default policies are not exported. Ownership
+  markers are removed from descriptions.
+
+Export Options:
+  -dir=<directory>
+    Directory to write the inventory to. Required.
+
+  -force
+    Overwrite inventory files that already exist. By default existing files
+    are skipped.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ExportCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), complete.Flags{
+		"-dir":   complete.PredictDirs("*"),
+		"-force": complete.PredictNothing,
+	})
+}
+
+func (c *ExportCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ExportCommand) Synopsis() string {
+	return "export writes the live vault configuration as inventory yaml"
+}
+
+func (c *ExportCommand) Name() string { return "export" }
+
+func (c *ExportCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.ioDir, "dir", "", "")
+	flagSet.BoolVar(&c.FlagForce, "force", false, "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if c.ioDir == "" {
+		fmt.Fprintf(os.Stderr, "-dir is required\n")
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+
+	c.reader = export.NewReader(c.Meta.SecretService)
+	c.reader.Warn = func(name string, err error) {
+		c.Meta.infof("(%s) some fields were not exported: %s\n", name, err)
+	}
+	c.report.Files = []exportResult{}
+	c.exportNamespace(c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace"))
+
+	err = c.Meta.output(&c.report, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Export complete: %d written, %d skipped, %d failed\n", c.report.Written, c.report.Skipped, c.report.Failed)
+		return err
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if c.report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportNamespace exports everything configured in ns, then each child
+// namespace
+func (c *ExportCommand) exportNamespace(ns string) {
+	c.exportMounts(ns)
+	c.exportAuths(ns)
+	c.exportPolicies(ns)
+
+	children, err := c.reader.Namespaces(ns)
+	if err != nil {
+		c.fail("vaultnamespace", err)
+		return
+	}
+	for _, name := range children {
+		spec, err := c.reader.Namespace(ns, name)
+		if err != nil {
+			c.fail("vaultnamespace", err)
+			continue
+		}
+		depth := namespaceDepth(spec.NamespaceBase, name)
+		c.write("vaultnamespace", "VaultNamespace", fmt.Sprintf("%d-%s", depth, export.FileName(strings.Trim(ns, "/"), name)), spec)
+		c.exportNamespace(strings.Trim(ns+"/"+name, "/"))
+	}
+}
+
+// exportMounts exports the secret engines of ns and the roles of its pki and
+// ssh engines
+func (c *ExportCommand) exportMounts(ns string) {
+	paths, err := c.reader.Endpoints(ns)
+	if err != nil {
+		c.fail("vaultendpoint", err)
+		return
+	}
+	for _, path := range paths {
+		if protectedNames[path+"/"] {
+			continue
+		}
+		spec, err := c.reader.Endpoint(ns, path)
+		if err != nil {
+			c.fail("vaultendpoint", err)
+			continue
+		}
+		c.write("vaultendpoint", "VaultEndpoint", export.FileName(export.Namespace(ns), path), spec)
+
+		if spec.MountOptions.Type != "pki" && spec.MountOptions.Type != "ssh" {
+			continue
+		}
+		roles, err := c.reader.EngineRoles(ns, path)
+		if err != nil {
+			c.fail(spec.MountOptions.Type+"role", err)
+			continue
+		}
+		for _, role := range roles {
+			name := export.FileName(export.Namespace(ns), path, role)
+			if spec.MountOptions.Type == "pki" {
+				roleSpec, err := c.reader.PKIRole(ns, path, role)
+				if err != nil {
+					c.fail("pkirole", err)
+					continue
+				}
+				c.write("pkirole", "PKIRole", name, roleSpec)
+				continue
+			}
+			roleSpec, err := c.reader.SSHRole(ns, path, role)
+			if err != nil {
+				c.fail("sshrole", err)
+				continue
+			}
+			c.write("sshrole", "SSHRole", name, roleSpec)
+		}
+	}
+}
+
+// exportAuths exports the auth methods of ns with their roles
+func (c *ExportCommand) exportAuths(ns string) {
+	paths, err := c.reader.Auths(ns)
+	if err != nil {
+		c.fail("vaultauth", err)
+		return
+	}
+	for _, path := range paths {
+		if protectedNames[path+"/"] {
+			continue
+		}
+		spec, err := c.reader.Auth(ns, path)
+		if err != nil {
+			c.fail("vaultauth", err)
+			continue
+		}
+		c.write("vaultauth", "VaultAuth", export.FileName(export.Namespace(ns), spec.Data.Type, path), spec)
+
+		roles, err := c.reader.AuthRoles(ns, path)
+		if err != nil {
+			c.fail("vaultrole", err)
+			continue
+		}
+		for _, role := range roles {
+			name := export.FileName(export.Namespace(ns), path, role)
+			if export.IsJWT(spec.Data.Type) {
+				roleSpec, err := c.reader.JWTRole(ns, path, role)
+				if err != nil {
+					c.fail("jwtrole", err)
+					continue
+				}
+				c.write("jwtrole", "JWTRole", name, roleSpec)
+				continue
+			}
+			roleSpec, err := c.reader.VaultRole(ns, path, role)
+			if err != nil {
+				c.fail("vaultrole", err)
+				continue
+			}
+			c.write("vaultrole", "VaultRole", name, roleSpec)
+		}
+	}
+}
+
+// exportPolicies exports the acl policies of ns
+func (c *ExportCommand) exportPolicies(ns string) {
+	names, err := c.reader.Policies(ns)
+	if err != nil {
+		c.fail("vaultpolicy", err)
+		return
+	}
+	for _, name := range names {
+		if protectedNames[name] {
+			continue
+		}
+		spec, err := c.reader.Policy(ns, name)
+		if err != nil {
+			c.fail("vaultpolicy", err)
+			continue
+		}
+		c.write("vaultpolicy", "VaultPolicy", export.FileName(export.Namespace(ns), name), spec)
+	}
+}
+
+// write writes the inventory document for spec to <dir>/<kindDir>/<name>.yaml
+func (c *ExportCommand) write(kindDir, kind, name string, spec interface{}) {
+	filename := filepath.Join(c.ioDir, kindDir, name+".yaml")
+	if _, err := os.Stat(filename); err == nil && !c.FlagForce {
+		c.Meta.infof("%s: %s exists, skipped\n", kindDir, filename)
+		c.report.Skipped++
+		c.report.Files = append(c.report.Files, exportResult{Kind: kind, File: filename, Status: "skipped"})
+		return
+	}
+	export.StripMarkers(spec)
+	out, err := export.Marshal(kind, name, spec)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(filename), 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filename, out, 0644)
+	}
+	if err != nil {
+		c.Meta.infof("%s: unable to write %s: %s\n", kindDir, filename, err)
+		c.report.Failed++
+		c.report.Files = append(c.report.Files, exportResult{Kind: kind, File: filename, Status: "failed", Error: err.Error()})
+		return
+	}
+	c.Meta.infof("%s: %s written\n", kindDir, filename)
+	c.report.Written++
+	c.report.Files = append(c.report.Files, exportResult{Kind: kind, File: filename, Status: "written"})
+}
+
+func (c *ExportCommand) fail(kindDir string, err error) {
+	c.Meta.infof("%s: %s\n", kindDir, err)
+	c.report.Failed++
+	c.report.Files = append(c.report.Files, exportResult{Kind: kindDir, Status: "failed", Error: err.Error()})
+}
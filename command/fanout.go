@@ -0,0 +1,267 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ibm/vault-cli/pkg/fanout"
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// FanOutCommand runs Command once per context when -context is a comma
+// separated list or a glob. Every context runs in a vault-cli process of
+// its own, so that contexts do not share clients, output or events.
+type FanOutCommand struct {
+	cli.Command
+	Meta Meta
+	// name is the command line of the command, like "put vaultpolicy"
+	name string
+}
+
+// fanOutFactory wraps the commands of factory in a FanOutCommand
+func fanOutFactory(meta Meta, name string, factory cli.CommandFactory) cli.CommandFactory {
+	return func() (cli.Command, error) {
+		command, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		return &FanOutCommand{Command: command, Meta: meta, name: name}, nil
+	}
+}
+
+// fanOutResult is the outcome of the command in one context
+type fanOutResult struct {
+	Context  string      `json:"context"`
+	Cluster  string      `json:"cluster"`
+	Server   string      `json:"server"`
+	ExitCode int         `json:"exitCode"`
+	Duration float64     `json:"duration"`
+	Result   interface{} `json:"result,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// fanOutReport is what a fanned out command prints
+type fanOutReport struct {
+	Contexts  []fanOutResult `json:"contexts"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+}
+
+func (c *FanOutCommand) AutocompleteFlags() complete.Flags {
+	flags := complete.Flags{"-parallelism": complete.PredictAnything}
+	if command, ok := c.Command.(cli.CommandAutocomplete); ok {
+		return mergeAutocompleteFlags(command.AutocompleteFlags(), flags)
+	}
+	return flags
+}
+
+func (c *FanOutCommand) AutocompleteArgs() complete.Predictor {
+	if command, ok := c.Command.(cli.CommandAutocomplete); ok {
+		return command.AutocompleteArgs()
+	}
+	return complete.PredictNothing
+}
+
+func (c *FanOutCommand) Name() string { return c.name }
+
+func (c *FanOutCommand) Run(args []string) int {
+	given, args, err := takeFlags(args, map[string]string{"parallelism": "parallelism"})
+	if err != nil {
+		c.Meta.Ui.Error(err.Error())
+		return 1
+	}
+	parallelism := fanout.DefaultParallelism
+	if value, ok := given["parallelism"]; ok {
+		parallelism, err = strconv.Atoi(value)
+		if err != nil || parallelism < 1 {
+			c.Meta.Ui.Error(fmt.Sprintf("-parallelism must be a positive number, got %q", value))
+			return 1
+		}
+	}
+	given, childArgs, err := takeFlags(args, map[string]string{
+		"c": "context", "context": "context", "o": "output", "output": "output",
+		"events": "events", "junit": "junit", "out": "out", "config": "config",
+	})
+	if err != nil {
+		c.Meta.Ui.Error(err.Error())
+		return 1
+	}
+	spec := given["context"]
+	if spec == "" {
+		spec = os.Getenv(envVaultCLIContext)
+	}
+	if !fanout.IsList(spec) {
+		return c.Command.Run(args)
+	}
+
+	c.Meta.flagConfigPath = given["config"]
+	c.Meta.outputFormat = given["output"]
+	if _, err := c.Meta.loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s", err)
+		return 1
+	}
+	contexts, err := fanout.Match(spec, c.Meta.contextNames())
+	if err != nil {
+		c.Meta.Ui.Error(err.Error())
+		return 1
+	}
+	printer, err := output.New(c.Meta.outputFormat)
+	if err != nil {
+		c.Meta.Ui.Error(err.Error())
+		return 1
+	}
+	c.Meta.printer = printer
+	executable, err := os.Executable()
+	if err != nil {
+		c.Meta.Ui.Error(fmt.Sprintf("cannot run the command per context: %s", err))
+		return 1
+	}
+
+	// the flags go before the args of the command, where flag parsing
+	// stops
+	results := fanout.Run(contexts, parallelism, func(context string) fanout.Result {
+		args := append(strings.Fields(c.name), "-context", context)
+		if path := given["config"]; path != "" {
+			args = append(args, "-config", path)
+		}
+		if printer.Structured() {
+			args = append(args, "-output", "json")
+		}
+		for _, flag := range []string{"events", "junit", "out"} {
+			if path := given[flag]; path != "" {
+				args = append(args, "-"+flag, contextPath(path, context))
+			}
+		}
+		return runContext(executable, append(args, childArgs...))
+	})
+
+	report := &fanOutReport{Contexts: []fanOutResult{}}
+	for _, result := range results {
+		r := fanOutResult{
+			Context:  result.Context,
+			ExitCode: result.ExitCode,
+			Duration: result.Duration.Seconds(),
+		}
+		if ctx := c.Meta.Config.GetContextByName(result.Context); ctx != nil {
+			r.Cluster = ctx.Cluster
+			if cluster := c.Meta.Config.GetClusterByName(ctx.Cluster); cluster != nil {
+				r.Server = cluster.Server
+			}
+		}
+		if result.ExitCode != 0 {
+			r.Error = strings.TrimSpace(string(result.Stderr))
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		if printer.Structured() {
+			// the json document of the context, its text when it failed
+			// before printing one
+			if err := json.Unmarshal(result.Stdout, &r.Result); err != nil && len(result.Stdout) > 0 {
+				r.Result = string(result.Stdout)
+			}
+		} else {
+			fmt.Printf("==> %s\n", result.Context)
+			os.Stdout.Write(result.Stdout)
+			os.Stderr.Write(result.Stderr)
+		}
+		report.Contexts = append(report.Contexts, r)
+	}
+
+	err = c.Meta.output(report, func(w io.Writer) error {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+		fmt.Fprintf(tw, "CONTEXT\tCLUSTER\tSERVER\tSTATUS\tDURATION\n")
+		for _, r := range report.Contexts {
+			status := "ok"
+			if r.ExitCode != 0 {
+				status = fmt.Sprintf("failed (exit %d)", r.ExitCode)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1fs\n", r.Context, r.Cluster, r.Server, status, r.Duration)
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "%d contexts: %d succeeded, %d failed\n", len(report.Contexts), report.Succeeded, report.Failed)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return fanout.ExitCode(results)
+}
+
+// runContext runs vault-cli with args and captures its output. The command
+// cannot prompt, stdin is empty.
+func runContext(executable string, args []string) fanout.Result {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	result := fanout.Result{}
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.ExitCode = 1
+			fmt.Fprintf(&stderr, "%s\n", err)
+		}
+	}
+	result.Stdout, result.Stderr = stdout.Bytes(), stderr.Bytes()
+	return result
+}
+
+// contextPath returns the file of a context for the events, junit or plan
+// file path, like events-prod.jsonl for events.jsonl. Stdout stays stdout.
+func contextPath(path, context string) string {
+	if path == "-" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + context + ext
+}
+
+// takeFlags removes the flags in names from args and returns their values
+// by the canonical name names maps them to, and the remaining args
+func takeFlags(args []string, names map[string]string) (map[string]string, []string, error) {
+	given := map[string]string{}
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		name, value := strings.TrimLeft(arg, "-"), ""
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		canonical, ok := names[name]
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		given[canonical] = value
+	}
+	return given, rest, nil
+}
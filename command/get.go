@@ -0,0 +1,371 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/posener/complete"
+)
+
+type GetCommand struct {
+	Meta Meta
+}
+
+func (c *GetCommand) Help() string {
+	helpText := `
+Usage: vault-cli get [options] <kind> [name]
+
+  Reads live objects from vault, in the context namespace or -namespace,
+  and prints them as the spec of their inventory kind. Without a name every
+  object of the kind is listed.
+
+  Kinds and names:
+    vaultnamespace   child namespace name
+    vaultendpoint    mount path, e.g. pki
+    vaultauth        auth method path, e.g. jwt
+    vaultpolicy      policy name
+    vaultrole        <auth path>/<role>, e.g. myauth/operator
+    jwtrole          <auth path>/<role>, e.g. jwt/operator
+    pkirole          <pki path>/<role>, e.g. pki/tls
+    sshrole          <ssh path>/<role>, e.g. ssh/operator
+    secret           kv path, e.g. secret/demo/password. A name is required.
+                     Only key names are shown, never values.
+
+  The text output is a table. The json and yaml output are inventory
+  documents, which put reads back.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *GetCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *GetCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet(liveKindNames()...)
+}
+
+func (c *GetCommand) Synopsis() string {
+	return "get reads live vault objects as inventory kinds"
+}
+
+func (c *GetCommand) Name() string { return "get" }
+
+func (c *GetCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Meta.Ui.Error("This command takes one or two arguments: <kind> [name]")
+		return 1
+	}
+	lk, ok := liveKinds[strings.ToLower(args[0])]
+	if !ok {
+		c.Meta.Ui.Error(fmt.Sprintf("unknown kind %s, expected one of %s", args[0], strings.Join(liveKindNames(), ", ")))
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+
+	r := export.NewReader(c.Meta.SecretService)
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	names := args[1:]
+	if len(names) == 0 {
+		names, err = lk.list(r, ns)
+		if err != nil {
+			fmt.Printf("%s\n", err)
+			return 1
+		}
+	}
+
+	objects := []*liveObject{}
+	for _, name := range names {
+		o, err := lk.read(r, ns, name)
+		if err != nil {
+			fmt.Printf("%s\n", err)
+			return 1
+		}
+		export.StripMarkers(o.spec)
+		objects = append(objects, o)
+	}
+
+	docs := []*export.Document{}
+	for _, o := range objects {
+		docs = append(docs, o.document())
+	}
+	var v interface{} = docs
+	if len(args) == 2 {
+		v = docs[0]
+	}
+	err = c.Meta.output(v, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, o := range objects {
+			rows = append(rows, []string{o.kind, export.Namespace(o.namespace), o.name, o.path})
+		}
+		return output.Table(w, []string{"KIND", "NAMESPACE", "NAME", "PATH"}, rows)
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// liveObject is a vault object read back as the spec of its inventory kind
+type liveObject struct {
+	kind      string
+	namespace string
+	name      string
+	// path is the vault path of the object, as recorded in the ownership
+	// index
+	path string
+	spec interface{}
+}
+
+// document returns o as an inventory document named the way export names
+// its file
+func (o *liveObject) document() *export.Document {
+	return export.NewDocument(o.kind, export.FileName(export.Namespace(o.namespace), o.name), o.spec)
+}
+
+// liveKind lists and reads the live objects of one inventory kind
+type liveKind struct {
+	list func(r *export.Reader, ns string) ([]string, error)
+	read func(r *export.Reader, ns, name string) (*liveObject, error)
+}
+
+// liveKinds are the inventory kinds get and describe read, by kind directory
+var liveKinds = map[string]liveKind{
+	"vaultnamespace": {
+		list: func(r *export.Reader, ns string) ([]string, error) { return r.Namespaces(ns) },
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			spec, err := r.Namespace(ns, name)
+			return newLiveObject("VaultNamespace", ns, name, "sys/namespaces/"+name, spec, err)
+		},
+	},
+	"vaultendpoint": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			names, err := r.Endpoints(ns)
+			return unprotected(names, "/"), err
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			spec, err := r.Endpoint(ns, name)
+			return newLiveObject("VaultEndpoint", ns, name, "sys/mounts/"+strings.Trim(name, "/"), spec, err)
+		},
+	},
+	"vaultauth": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			names, err := r.Auths(ns)
+			return unprotected(names, "/"), err
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			spec, err := r.Auth(ns, name)
+			return newLiveObject("VaultAuth", ns, name, "sys/auth/"+strings.Trim(name, "/"), spec, err)
+		},
+	},
+	"vaultpolicy": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			names, err := r.Policies(ns)
+			return unprotected(names, ""), err
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			spec, err := r.Policy(ns, name)
+			return newLiveObject("VaultPolicy", ns, name, "sys/policy/"+name, spec, err)
+		},
+	},
+	"vaultrole": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			return listAuthRoles(r, ns, false)
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			authPath, role, err := authRole(r, ns, name, false)
+			if err != nil {
+				return nil, err
+			}
+			spec, err := r.VaultRole(ns, authPath, role)
+			return newLiveObject("VaultRole", ns, name, fmt.Sprintf("auth/%s/role/%s", authPath, role), spec, err)
+		},
+	},
+	"jwtrole": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			return listAuthRoles(r, ns, true)
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			authPath, role, err := authRole(r, ns, name, true)
+			if err != nil {
+				return nil, err
+			}
+			spec, err := r.JWTRole(ns, authPath, role)
+			return newLiveObject("JWTRole", ns, name, fmt.Sprintf("auth/%s/role/%s", authPath, role), spec, err)
+		},
+	},
+	"pkirole": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			return listEngineRoles(r, ns, "pki")
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			path, role, err := splitRole(name)
+			if err != nil {
+				return nil, err
+			}
+			spec, err := r.PKIRole(ns, path, role)
+			return newLiveObject("PKIRole", ns, name, fmt.Sprintf("%s/roles/%s", path, role), spec, err)
+		},
+	},
+	"sshrole": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			return listEngineRoles(r, ns, "ssh")
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			path, role, err := splitRole(name)
+			if err != nil {
+				return nil, err
+			}
+			spec, err := r.SSHRole(ns, path, role)
+			return newLiveObject("SSHRole", ns, name, fmt.Sprintf("%s/roles/%s", path, role), spec, err)
+		},
+	},
+	"secret": {
+		list: func(r *export.Reader, ns string) ([]string, error) {
+			return nil, fmt.Errorf("secrets are not listed, give the kv path of a secret")
+		},
+		read: func(r *export.Reader, ns, name string) (*liveObject, error) {
+			spec, _, err := r.Secret(ns, name)
+			return newLiveObject("SecretMeta", ns, name, strings.Trim(name, "/"), spec, err)
+		},
+	},
+}
+
+func newLiveObject(kind, ns, name, path string, spec interface{}, err error) (*liveObject, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &liveObject{kind: kind, namespace: ns, name: strings.Trim(name, "/"), path: path, spec: spec}, nil
+}
+
+func liveKindNames() []string {
+	names := []string{}
+	for k := range liveKinds {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unprotected drops the builtin mounts and policies vault-cli never manages.
+// Mount paths are protected with their trailing suffix "/".
+func unprotected(names []string, suffix string) []string {
+	kept := []string{}
+	for _, n := range names {
+		if !protectedNames[n+suffix] {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// splitRole splits a role name given as <mount path>/<role>
+func splitRole(name string) (string, string, error) {
+	name = strings.Trim(name, "/")
+	i := strings.LastIndex(name, "/")
+	if i < 1 {
+		return "", "", fmt.Errorf("role %s must be given as <path>/<role>", name)
+	}
+	return name[:i], name[i+1:], nil
+}
+
+// authRole splits the role name and checks that the auth method has the jwt
+// or non jwt roles asked for
+func authRole(r *export.Reader, ns, name string, jwt bool) (string, string, error) {
+	authPath, role, err := splitRole(name)
+	if err != nil {
+		return "", "", err
+	}
+	authType, err := r.AuthType(ns, authPath)
+	if err != nil {
+		return "", "", err
+	}
+	if export.IsJWT(authType) != jwt {
+		kind := "vaultrole"
+		if export.IsJWT(authType) {
+			kind = "jwtrole"
+		}
+		return "", "", fmt.Errorf("auth/%s is a %s auth method, get its roles as %s", authPath, authType, kind)
+	}
+	return authPath, role, nil
+}
+
+// listAuthRoles lists the roles of the jwt or the non jwt auth methods of ns
+// as <auth path>/<role>
+func listAuthRoles(r *export.Reader, ns string, jwt bool) ([]string, error) {
+	paths, err := r.Auths(ns)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, p := range unprotected(paths, "/") {
+		authType, err := r.AuthType(ns, p)
+		if err != nil {
+			return nil, err
+		}
+		if export.IsJWT(authType) != jwt {
+			continue
+		}
+		roles, err := r.AuthRoles(ns, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			names = append(names, p+"/"+role)
+		}
+	}
+	return names, nil
+}
+
+// listEngineRoles lists the roles of the secret engines of type engineType
+// in ns as <mount path>/<role>
+func listEngineRoles(r *export.Reader, ns, engineType string) ([]string, error) {
+	paths, err := r.Endpoints(ns)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, p := range unprotected(paths, "/") {
+		t, err := r.EndpointType(ns, p)
+		if err != nil {
+			return nil, err
+		}
+		if t != engineType {
+			continue
+		}
+		roles, err := r.EngineRoles(ns, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			names = append(names, p+"/"+role)
+		}
+	}
+	return names, nil
+}
@@ -3,13 +3,19 @@ package command
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
 
+	"github.com/ibm/vault-cli/pkg/inventory"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
+	"gopkg.in/yaml.v2"
 )
 
 const (
 	envVaultCLIConfigDir  = "VAULTCLICONFIG"
+	envVaultCLIContext    = "VAULTCLI_CONTEXT"
 	configDefaultDir      = ".vaultcli"
 	configDefaultFileName = "config.yaml"
 )
@@ -25,6 +31,17 @@ func mergeAutocompleteFlags(flags ...complete.Flags) complete.Flags {
 	return merged
 }
 
+// flagGiven reports whether the flag name was set on the command line
+func flagGiven(f *flag.FlagSet, name string) bool {
+	given := false
+	f.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
 // uiErrorWriter is a io.Writer that wraps underlying ui.ErrorWriter().
 // ui.ErrorWriter expects full lines as inputs and it emits its own line breaks.
 //
@@ -65,3 +82,53 @@ func (w *uiErrorWriter) Close() error {
 	}
 	return nil
 }
+
+// renderInventoryFile reads the named file from the kind directory of the
+// current context inventory, applies the -data template values and
+// unmarshals the resulting yaml into obj
+func (m *Meta) renderInventoryFile(kindDir, f, tplName string, obj interface{}) error {
+	filename := m.CurrentContext.InventoryPath + "/" + kindDir + "/" + f
+	data, err := inventory.ReadFile(filename + ".yaml")
+	if err != nil {
+		return fmt.Errorf("error reading file: %s", err)
+	}
+	yamlbytes, err := m.TemplateService.Exec(tplName, data, m.templateData())
+	if err != nil {
+		return fmt.Errorf("unable to apply template to %s: %s", kindDir, err)
+	}
+	err = yaml.Unmarshal(yamlbytes, obj)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %s", kindDir, err)
+	}
+	return nil
+}
+
+// templateData returns the -data values of the inventory templates with
+// the Context, Cluster and Server of the current context, unless -data
+// sets them
+func (m *Meta) templateData() string {
+	values := map[string]interface{}{}
+	if m.flagData != "" {
+		if err := json.Unmarshal([]byte(m.flagData), &values); err != nil {
+			// the template service reports the invalid json
+			return m.flagData
+		}
+	}
+	context := map[string]string{}
+	if ctx := m.CurrentContext; ctx != nil {
+		context["Context"], context["Cluster"] = ctx.Name, ctx.Cluster
+		if cluster := m.Config.GetClusterByName(ctx.Cluster); cluster != nil {
+			context["Server"] = cluster.Server
+		}
+	}
+	for k, v := range context {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return m.flagData
+	}
+	return string(data)
+}
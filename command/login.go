@@ -0,0 +1,98 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/posener/complete"
+)
+
+type LoginCommand struct {
+	Meta Meta
+}
+
+// loginResult is what login prints
+type loginResult struct {
+	Context   string `json:"context"`
+	User      string `json:"user"`
+	Expires   string `json:"expires,omitempty"`
+	Renewable bool   `json:"renewable"`
+}
+
+func (c *LoginCommand) Help() string {
+	helpText := `
+Usage: vault-cli login [options]
+
+  Logs in to the context with the auth method of its user and saves the new
+  session in the config file, even when the current session has not
+  expired. A token user looks up its token again.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *LoginCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *LoginCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *LoginCommand) Synopsis() string {
+	return "login starts a new session in the context"
+}
+
+func (c *LoginCommand) Name() string { return "login" }
+
+func (c *LoginCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	ctx, configPath, err := c.Meta.loadContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	session, err := c.Meta.Config.GetSession(c.Meta.ConfigService, configPath, ctx.Name, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to log in to context %s: %s\n", ctx.Name, err)
+		return 1
+	}
+
+	result := &loginResult{Context: ctx.Name, User: ctx.User}
+	if session.Expires != nil && *session.Expires > 0 {
+		result.Expires = time.Unix(*session.Expires, 0).Format(time.RFC3339)
+	}
+	if session.Renewable != nil {
+		result.Renewable = *session.Renewable
+	}
+	err = c.Meta.output(result, func(w io.Writer) error {
+		return output.Table(w, []string{"CONTEXT", "USER", "SESSION EXPIRES", "RENEWABLE"}, [][]string{
+			{result.Context, result.User, result.Expires, fmt.Sprintf("%t", result.Renewable)},
+		})
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
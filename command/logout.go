@@ -0,0 +1,157 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/posener/complete"
+)
+
+type LogoutCommand struct {
+	Meta Meta
+}
+
+// logoutResult is what logout prints
+type logoutResult struct {
+	Context string `json:"context"`
+	Revoked bool   `json:"revoked"`
+}
+
+func (c *LogoutCommand) Help() string {
+	helpText := `
+Usage: vault-cli logout [options]
+
+  Revokes the token of the session of the context and clears the session in
+  the config file. The next command logs in again.
+
+  Only a token vault-cli got from a login is revoked. The token of a token
+  user or one written into the config file is cleared but not revoked, and
+  logout refuses to clear a root token. A token that expired or was already
+  revoked is only cleared.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *LogoutCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *LogoutCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *LogoutCommand) Synopsis() string {
+	return "logout revokes and clears the session of the context"
+}
+
+func (c *LogoutCommand) Name() string { return "logout" }
+
+func (c *LogoutCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	ctx, configPath, err := c.Meta.loadContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	// the saved session is revoked as is, logging in to revoke it would
+	// start a new one
+	client, err := c.Meta.Config.SessionClient(ctx.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	result := &logoutResult{Context: ctx.Name}
+	if client.Token() != "" {
+		revoke, err := c.revocable(client)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to log out of context %s: %s\n", ctx.Name, err)
+			return 1
+		}
+		if revoke {
+			err = client.Auth().Token().RevokeSelf("")
+			if forbidden(err) {
+				err = nil
+			} else if err == nil {
+				result.Revoked = true
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "unable to revoke the token of context %s: %s\n", ctx.Name, err)
+				return 1
+			}
+		}
+	}
+
+	err = c.Meta.Config.StopSession(c.Meta.ConfigService, configPath, ctx.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	err = c.Meta.output(result, func(w io.Writer) error {
+		if result.Revoked {
+			fmt.Fprintf(w, "Logged out of context %s, the token is revoked\n", result.Context)
+		} else {
+			fmt.Fprintf(w, "Logged out of context %s\n", result.Context)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// revocable looks up the token of client and reports whether logout revokes
+// it: only tokens an auth method other than token issued at a login are.
+// A root token is refused, whoever saved it in the session, and a token
+// that expired or was revoked needs no revoking.
+func (c *LogoutCommand) revocable(client *api.Client) (bool, error) {
+	self, err := client.Auth().Token().LookupSelf()
+	if forbidden(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	policies, err := self.TokenPolicies()
+	if err != nil {
+		return false, err
+	}
+	for _, policy := range policies {
+		if policy == "root" {
+			return false, errors.New("its token has the root policy, revoke it with vault token revoke if you mean to")
+		}
+	}
+	path, _ := self.Data["path"].(string)
+	return strings.HasPrefix(path, "auth/") && !strings.HasPrefix(path, "auth/token/"), nil
+}
+
+// forbidden reports whether err is vault denying the token, which an
+// expired or revoked token gets
+func forbidden(err error) bool {
+	respErr, ok := err.(*api.ResponseError)
+	return ok && respErr.StatusCode == http.StatusForbidden
+}
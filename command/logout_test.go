@@ -0,0 +1,123 @@
+package command_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ibm/vault-cli/command"
+	"github.com/ibm/vault-cli/pkg/configservice/configfile"
+	"github.com/mitchellh/cli"
+)
+
+const logoutConfig = `apiVersion: v1
+kind: Config
+contexts:
+- name: dev
+  context:
+    cluster: local
+    namespace: root
+    user: bob
+    session:
+      token: %s
+      lease-duration: 3600
+      expires: 2582395696
+clusters:
+- name: local
+  cluster:
+    server: %s
+current-context: dev
+users:
+- name: bob
+  user:
+    username: bob
+    password: pw
+`
+
+func TestLogout(t *testing.T) {
+	t.Parallel()
+
+	// s.root is a root token, s.created one made with vault token create and
+	// s.login one of a userpass login
+	var mu sync.Mutex
+	revoked := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Vault-Token")
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			lookup := map[string]string{
+				"s.root":    `{"data":{"policies":["root"],"path":"auth/token/root"}}`,
+				"s.created": `{"data":{"policies":["default"],"path":"auth/token/create"}}`,
+				"s.login":   `{"data":{"policies":["default"],"path":"auth/userpass/login/bob"}}`,
+			}[token]
+			if lookup == "" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(lookup))
+		case "/v1/auth/token/revoke-self":
+			mu.Lock()
+			revoked = append(revoked, token)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		token   string
+		code    int
+		revoked bool
+		cleared bool
+	}{
+		{token: "s.root", code: 1},
+		{token: "s.created", cleared: true},
+		{token: "s.expired", cleared: true},
+		{token: "s.login", revoked: true, cleared: true},
+	} {
+		dir, err := ioutil.TempDir("", "vault-cli")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		configPath := filepath.Join(dir, "config.yaml")
+		if err := ioutil.WriteFile(configPath, []byte(fmt.Sprintf(logoutConfig, tc.token, server.URL)), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		meta := &command.Meta{ConfigService: configfile.NewConfigFileService()}
+		logout, err := command.Commands(meta, &cli.MockUi{})["logout"]()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := logout.Run([]string{"-config", configPath, "-o", "json"}); code != tc.code {
+			t.Errorf("%s: expected exit code %d, got %d", tc.token, tc.code, code)
+		}
+
+		mu.Lock()
+		wasRevoked := len(revoked) > 0 && revoked[len(revoked)-1] == tc.token
+		mu.Unlock()
+		if wasRevoked != tc.revoked {
+			t.Errorf("%s: expected revoked %t", tc.token, tc.revoked)
+		}
+		saved, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cleared := !strings.Contains(string(saved), tc.token); cleared != tc.cleared {
+			t.Errorf("%s: expected cleared %t, got config\n%s", tc.token, tc.cleared, saved)
+		}
+	}
+	if len(revoked) != 1 {
+		t.Errorf("expected only the login token to be revoked, got %v", revoked)
+	}
+}
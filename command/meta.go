@@ -4,11 +4,17 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/config"
 	"github.com/ibm/vault-cli/pkg/configservice"
+	"github.com/ibm/vault-cli/pkg/credstore"
+	"github.com/ibm/vault-cli/pkg/events"
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/ibm/vault-cli/pkg/owner"
 	"github.com/ibm/vault-cli/pkg/secretservice"
 	"github.com/ibm/vault-cli/pkg/templateservice"
 	"github.com/mitchellh/cli"
@@ -35,6 +41,10 @@ type Meta struct {
 
 	CurrentContext *config.Context
 
+	// Owners is the ownership index of the current context, nil when the
+	// context has no ownershipPath
+	Owners *owner.Index
+
 	// // These are set by the command line flags.
 	// context is the context name to use for this command
 	currentContextName string
@@ -46,6 +56,19 @@ type Meta struct {
 	name          string
 	outputFormat  string
 	InventoryPath string
+
+	// printer prints results in the -output format
+	printer *output.Printer
+
+	// eventsPath and junitPath are set by the -events and -junit flags of
+	// the commands that put inventory files
+	eventsPath string
+	junitPath  string
+	// events records what each put did, nil unless -events or -junit is set
+	events     *events.Recorder
+	eventsFile *os.File
+	// puts are the results of the inventory files putFile put
+	puts []putResult
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -56,8 +79,8 @@ func (m *Meta) FlagSet(n string) *flag.FlagSet {
 	f := flag.NewFlagSet(n, flag.ContinueOnError)
 
 	f.StringVar(&m.flagConfigPath, "config", "", "")
-	f.StringVar(&m.currentContextName, "c", "local", "")
-	f.StringVar(&m.currentContextName, "context", "local", "")
+	f.StringVar(&m.currentContextName, "c", "", "")
+	f.StringVar(&m.currentContextName, "context", "", "")
 	f.StringVar(&m.flagData, "data", "", "")
 	f.StringVar(&m.flagData, "d", "", "")
 	f.StringVar(&m.namespace, "n", "", "")
@@ -89,10 +112,17 @@ func generalOptionsUsage() string {
 
 	helpText := `
   -context=<contextname>
-    The name of the context to use for this run of the command
+    The name of the context to use for this run of the command. Overrides
+    the VAULTCLI_CONTEXT environment variable if set, which overrides the
+    current-context of the config file.
+    put, apply and plan take a comma separated list or a glob of contexts,
+    like -c 'prod-*', and run in each of them, see -parallelism.
     Alias: -c
   -config=<vault-cli-config path>
-    The location if the cli config yaml file. Defaults to "~/.vault-cli/config.yaml"
+    The directory of the cli config.yaml file. Defaults to "~/.vaultcli",
+    overrides the VAULTCLICONFIG environment variable if set. Like
+    KUBECONFIG it can be a ":" separated list of directories and yaml files
+    that are merged, the first definition of a name wins.
 
   -data=<json or file>
     The data in json format either as escaped string or if the first character is
@@ -105,16 +135,57 @@ func generalOptionsUsage() string {
     to user.
     Defaults to the "default" namespace.
 
+  -parallelism=<n>
+    How many contexts of a -context list or glob put, apply and plan run
+    in at once. Each context runs in a vault-cli process of its own without
+    stdin, so commands that ask for confirmation need -auto-approve. The
+    -events and -junit files get the context name appended. Defaults to 4.
+
   -no-color
     Disables colored command output. Alternatively, VAULT_CLI_NO_COLOR may be
     set.
 
-  -output=<json|yaml|text>
+  -output=<text|json|yaml|jsonpath=<expression>|template=<template>>
+    The format of the command result. text is for people, the others print
+    a single document on stdout for scripts and move progress to stderr.
+    jsonpath and go template expressions see the json document, e.g.
+    -o jsonpath='{.resources[*].file}'.
     Alias: -o
 `
 	return strings.TrimSpace(helpText)
 }
 
+// output prints v in the -output format, text writing its text form. The
+// text form goes to the infoWriter, so that it stays out of events on
+// stdout.
+func (m *Meta) output(v interface{}, text func(w io.Writer) error) error {
+	if m.printer == nil {
+		printer, err := output.New(m.outputFormat)
+		if err != nil {
+			return err
+		}
+		m.printer = printer
+	}
+	if !m.printer.Structured() {
+		return m.printer.Print(m.infoWriter(), v, text)
+	}
+	return m.printer.Print(os.Stdout, v, text)
+}
+
+// infoWriter is where progress for people goes: stdout for text output and
+// stderr when stdout holds a document or events for scripts
+func (m *Meta) infoWriter() io.Writer {
+	if m.printer != nil && m.printer.Structured() || m.eventsPath == "-" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// infof prints progress for people to the infoWriter
+func (m *Meta) infof(format string, args ...interface{}) {
+	fmt.Fprintf(m.infoWriter(), format, args...)
+}
+
 // funcVar is a type of flag that accepts a function that is the string given
 // by the user.
 type funcVar func(s string) error
@@ -124,34 +195,105 @@ func (f funcVar) String() string     { return "" }
 func (f funcVar) IsBoolFlag() bool   { return false }
 
 func (m *Meta) Load() error {
-	configPath, err := m.getConfigPath()
+	ctx, configPath, err := m.loadContext()
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error getting config path: %s\n", err.Error()))
+		return err
 	}
-	cfg, err := m.ConfigService.Read(configPath)
+
+	secretsvc, err := m.Config.GetServiceFromContext(ctx, m.ConfigService, configPath, m.namespace)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error reading config: %s\n", err.Error()))
+		return errors.New(fmt.Sprintf("Error getting service from config: %s\n", err.Error()))
+	}
+	m.SecretService = secretsvc
+
+	// long runs outlive the session, the watcher renews it or logs in again
+	// and ends with the process
+	_, err = m.Config.WatchSession(m.ConfigService, secretsvc, configPath, ctx.Name, func(format string, args ...interface{}) {
+		m.Ui.Warn(fmt.Sprintf(format, args...))
+	})
+	if err != nil {
+		return err
 	}
-	m.Config = cfg
 
-	ctx := cfg.GetContextByName(m.currentContextName)
+	if ctx.OwnershipPath != "" {
+		ns := ctx.Namespace
+		if ns == "root" {
+			ns = ""
+		}
+		m.Owners = owner.NewIndex(secretsvc, ns, ctx.OwnershipPath)
+	}
+	return m.openEvents()
+}
+
+// loadContext loads the config and returns the context to use, without
+// logging in to it, and the config path
+func (m *Meta) loadContext() (*config.Context, string, error) {
+	printer, err := output.New(m.outputFormat)
+	if err != nil {
+		return nil, "", err
+	}
+	m.printer = printer
+
+	configPath, err := m.loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	name, from := m.contextName()
+	if name == "" {
+		return nil, "", fmt.Errorf("no context given, use -c, %s or vault-cli config use-context, available contexts: %s", envVaultCLIContext, strings.Join(m.contextNames(), ", "))
+	}
+	ctx := m.Config.GetContextByName(name)
 	if ctx == nil {
-		return errors.New(fmt.Sprintf("could not find named context"))
+		return nil, "", fmt.Errorf("context %q from %s not found, available contexts: %s", name, from, strings.Join(m.contextNames(), ", "))
 	}
 	m.CurrentContext = ctx
+	return ctx, configPath, nil
+}
+
+// contextName returns the context to use and where its name is from: the
+// -context flag, then the VAULTCLI_CONTEXT environment variable, then the
+// current-context of the config file
+func (m *Meta) contextName() (string, string) {
+	if m.currentContextName != "" {
+		return m.currentContextName, "-context"
+	}
+	if name := os.Getenv(envVaultCLIContext); name != "" {
+		return name, envVaultCLIContext
+	}
+	return m.Config.CurrentContext, "current-context"
+}
 
-	secretsvc, err := m.Config.GetServiceFromContext(ctx, m.flagConfigPath, m.namespace)
+// loadConfig reads the config file into Config and returns its path. It is
+// all the config commands load, they work without a usable context.
+func (m *Meta) loadConfig() (string, error) {
+	configPath, err := m.getConfigPath()
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error getting service from config: %s\n", err.Error()))
+		return "", errors.New(fmt.Sprintf("Error getting config path: %s\n", err.Error()))
 	}
-	m.SecretService = secretsvc
-	return nil
+	cfg, err := m.ConfigService.Read(configPath)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Error reading config: %s\n", err.Error()))
+	}
+	cfg.Credentials = credstore.New(filepath.Dir(filepath.SplitList(configPath)[0]), m.askPassphrase)
+	m.Config = cfg
+	return configPath, nil
+}
+
+// askPassphrase asks for the passphrase of the encrypted credential file
+func (m *Meta) askPassphrase() (string, error) {
+	if m.printer != nil && m.printer.Structured() {
+		return "", fmt.Errorf("cannot ask for the credential file passphrase with -output %s, set %s", m.outputFormat, credstore.EnvPassphrase)
+	}
+	return m.Ui.AskSecret("Passphrase of the vault-cli credential file:")
 }
 
 // getConfigPath will set path based on:
 // if set by flag override other methods
 // if env variable set override default
 // default
+// The flag and env variable can be a list like KUBECONFIG of directories
+// holding a config.yaml and of yaml files, the path is then the list of
+// the files.
 func (m *Meta) getConfigPath() (string, error) {
 	var testDir string
 	if m.flagConfigPath != "" { // testDir is set by flag
@@ -165,12 +307,35 @@ func (m *Meta) getConfigPath() (string, error) {
 		}
 		testDir = home + "/" + configDefaultDir
 	}
-	if _, err := os.Stat(testDir); os.IsNotExist(err) {
-		err = os.Mkdir(testDir, 0755)
-		if err != nil {
-			return "", err
+	files := []string{}
+	for _, entry := range filepath.SplitList(testDir) {
+		if entry == "" {
+			continue
+		}
+		if isConfigFile(entry) {
+			files = append(files, entry)
+			continue
 		}
+		if _, err := os.Stat(entry); os.IsNotExist(err) {
+			err = os.Mkdir(entry, 0755)
+			if err != nil {
+				return "", err
+			}
+		}
+		files = append(files, entry+"/"+configDefaultFileName)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no config file in %q", testDir)
+	}
+	return strings.Join(files, string(os.PathListSeparator)), nil
+}
+
+// isConfigFile reports whether an entry of the config path is a file
+// rather than a directory holding config.yaml
+func isConfigFile(entry string) bool {
+	if info, err := os.Stat(entry); err == nil {
+		return !info.IsDir()
 	}
-	path := testDir + "/" + configDefaultFileName
-	return path, nil
+	ext := filepath.Ext(entry)
+	return ext == ".yaml" || ext == ".yml"
 }
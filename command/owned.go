@@ -0,0 +1,149 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/ibm/vault-cli/pkg/owner"
+	"github.com/posener/complete"
+)
+
+type OwnedCommand struct {
+	Meta Meta
+}
+
+func (c *OwnedCommand) Help() string {
+	helpText := `
+Usage: vault-cli owned [options]
+
+  Lists the vault objects written by vault-cli, with the context and
+  inventory file that last wrote them and when. The list is read from the
+  ownership index at the ownershipPath kv path of the context, in the
+  context namespace.
+
+  Every put records its objects in the index. Mounts and auth methods also
+  carry a "[vault-cli context=<context> file=<kind>/<file>]" marker at the
+  end of their description, and kv-v2 secrets carry vault-cli-context,
+  vault-cli-file and vault-cli-applied custom metadata.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OwnedCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *OwnedCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *OwnedCommand) Synopsis() string {
+	return "owned lists the vault objects managed by vault-cli"
+}
+
+func (c *OwnedCommand) Name() string { return "owned" }
+
+func (c *OwnedCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+	if c.Meta.Owners == nil {
+		fmt.Fprintf(os.Stderr, "context %s has no ownershipPath, no ownership index to list\n", c.Meta.CurrentContext.Name)
+		return 1
+	}
+
+	records, err := c.Meta.Owners.List()
+	if err != nil {
+		fmt.Printf("unable to list ownership index: %s\n", err)
+		return 1
+	}
+
+	err = c.Meta.output(records, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, r := range records {
+			rows = append(rows, []string{r.Kind, export.Namespace(r.Namespace), r.Path, r.File, r.Context, r.Applied.Format(time.RFC3339)})
+		}
+		return output.Table(w, []string{"KIND", "NAMESPACE", "PATH", "FILE", "CONTEXT", "APPLIED"}, rows)
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// claim returns an error when the object written by path is recorded in the
+// ownership index as written by another context or inventory file. Objects
+// with no record are adopted by the put.
+func (m *Meta) claim(kind, f, namespace, path string) error {
+	objectPath, ok := owner.ObjectPath(path)
+	if m.Owners == nil || !ok {
+		return nil
+	}
+	r, err := m.Owners.Get(namespace, objectPath)
+	if err != nil {
+		return fmt.Errorf("unable to read ownership index: %s", err)
+	}
+	if r != nil && (r.Context != m.CurrentContext.Name || r.Kind != kind || r.File != f) {
+		return fmt.Errorf("%s is managed by %s/%s in context %s", objectPath, r.Kind, r.File, r.Context)
+	}
+	return nil
+}
+
+// claimMarker returns an error when the description of a mount or auth
+// method carries the ownership marker of another context or inventory file
+func (m *Meta) claimMarker(kind, f, path, description string) error {
+	ctx, file, ok := owner.Parse(description)
+	if ok && (ctx != m.CurrentContext.Name || file != kind+"/"+f) {
+		return fmt.Errorf("%s is managed by %s in context %s", path, file, ctx)
+	}
+	return nil
+}
+
+// stamp records the inventory file as the owner of the object written by
+// path in the ownership index
+func (m *Meta) stamp(kind, f, namespace, path string) error {
+	objectPath, ok := owner.ObjectPath(path)
+	if m.Owners == nil || !ok {
+		return nil
+	}
+	err := m.Owners.Put(&owner.Record{
+		Kind:      kind,
+		Namespace: namespace,
+		Path:      objectPath,
+		File:      f,
+		Context:   m.CurrentContext.Name,
+		Applied:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update ownership index: %s", err)
+	}
+	return nil
+}
+
+// ownerMark is the description of a mount or auth method with the
+// ownership marker of the inventory file
+func (m *Meta) ownerMark(kind, f string, description interface{}) string {
+	d, _ := description.(string)
+	return owner.Mark(d, m.CurrentContext.Name, kind+"/"+f)
+}
@@ -0,0 +1,243 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/plan"
+	"github.com/posener/complete"
+)
+
+type PlanCommand struct {
+	Meta    Meta
+	ioDir   string
+	outFile string
+}
+
+func (c *PlanCommand) Help() string {
+	helpText := `
+Usage: vault-cli plan [options] [filespec]
+
+  Compares every kind found in the context inventory with the live state in
+  vault and prints the field level changes apply would make, in the order
+  apply would make them. Nothing is written.
+
+Plan Options:
+  -dir=<directory>
+    Directory holding one file per secretmeta key. secretmeta files are only
+    planned when this is set. Secret values are never printed.
+
+  -out=<file>
+    Save the planned writes, with a hash of each payload and a fingerprint
+    of the live state, so "vault-cli apply <file>" executes exactly them.
+    Plans that include secretmeta cannot be saved.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PlanCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), complete.Flags{
+		"-dir": complete.PredictDirs("*"),
+		"-out": complete.PredictFiles("*.json"),
+	})
+}
+
+func (c *PlanCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *PlanCommand) Synopsis() string {
+	return "plan shows the changes apply would make to vault"
+}
+
+func (c *PlanCommand) Name() string { return "plan" }
+
+func (c *PlanCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.StringVar(&c.ioDir, "dir", "", "")
+	flagSet.StringVar(&c.outFile, "out", "", "")
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	// process args
+	args = flagSet.Args()
+	filespec := "*"
+	if len(args) > 0 {
+		filespec = args[0]
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+
+	apply := &ApplyCommand{Meta: c.Meta, ioDir: c.ioDir}
+	changes, err := apply.planInventory(filespec)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	report := &planReport{Changes: plan.Redacted(changes), Summary: plan.Summary(changes)}
+	if c.outFile != "" {
+		err = plan.Save(c.outFile, &plan.File{
+			Version:  plan.FileVersion,
+			Context:  c.Meta.CurrentContext.Name,
+			Filespec: filespec,
+			Created:  time.Now().UTC(),
+			Changes:  changes,
+		})
+		if err != nil {
+			printChanges(c.Meta.infoWriter(), changes)
+			fmt.Printf("unable to save plan: %s\n", err)
+			return 1
+		}
+		report.SavedTo = c.outFile
+	}
+
+	err = c.Meta.output(report, func(w io.Writer) error {
+		printChanges(w, changes)
+		fmt.Fprintf(w, "Plan: %d to create, %d to update, %d unchanged\n",
+			report.Summary[plan.ActionCreate], report.Summary[plan.ActionUpdate], report.Summary[plan.ActionNoChange])
+		if report.SavedTo != "" {
+			fmt.Fprintf(w, "Plan saved to %s, run \"vault-cli apply -c %s %s\" to execute it\n", c.outFile, c.Meta.CurrentContext.Name, c.outFile)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// planReport is the result plan prints. Sensitive values are redacted.
+type planReport struct {
+	Changes []*plan.Change      `json:"changes"`
+	Summary map[plan.Action]int `json:"summary"`
+	SavedTo string              `json:"savedTo,omitempty"`
+}
+
+// planInventory plans every kind for the inventory files matching filespec
+// in the order apply writes them
+func (c *ApplyCommand) planInventory(filespec string) ([]*plan.Change, error) {
+	defaultNamespace := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+
+	changes := []*plan.Change{}
+	for _, kind := range c.kinds() {
+		files, err := c.inventoryFiles(kind, filespec)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			c.Meta.SecretService.GetClient().SetNamespace(defaultNamespace)
+			planned, err := kind.Plan(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: (%s) %s", kind.Dir, f, err)
+			}
+			changes = append(changes, planned...)
+		}
+	}
+	return changes, nil
+}
+
+// planChange reads the live object at the change path and compares it with
+// the change data
+func (m *Meta) planChange(change *plan.Change) error {
+	m.SecretService.GetClient().SetNamespace(change.Namespace)
+	secret, err := m.SecretService.Read(change.Path)
+	if err != nil {
+		return err
+	}
+	var current map[string]interface{}
+	if secret != nil {
+		current = secret.Data
+	}
+	change.SetCurrent(current)
+	return nil
+}
+
+// writeChange writes the change data to the change path in its namespace
+// and records the inventory file as its owner
+func (m *Meta) writeChange(change *plan.Change) error {
+	err := m.claim(change.Kind, change.File, change.Namespace, change.Path)
+	if err != nil {
+		return err
+	}
+	m.SecretService.GetClient().SetNamespace(change.Namespace)
+	_, err = m.SecretService.Write(change.Path, change.Data)
+	if err != nil {
+		return err
+	}
+	return m.stamp(change.Kind, change.File, change.Namespace, change.Path)
+}
+
+// dryRun prints the changes a put of the inventory file f would make. They
+// are progress for people, "vault-cli plan" prints them as a document.
+func (m *Meta) dryRun(f string, planFn func(f string) ([]*plan.Change, error)) error {
+	changes, err := planFn(f)
+	if err != nil {
+		return err
+	}
+	printChanges(m.infoWriter(), changes)
+	return nil
+}
+
+// printChanges prints each change with its field diffs
+func printChanges(w io.Writer, changes []*plan.Change) {
+	symbols := map[plan.Action]string{
+		plan.ActionCreate:   "+",
+		plan.ActionUpdate:   "~",
+		plan.ActionNoChange: " ",
+		plan.ActionDelete:   "-",
+	}
+	for _, c := range changes {
+		ns := c.Namespace
+		if ns == "" {
+			ns = "root"
+		}
+		if c.File == "" {
+			fmt.Fprintf(w, "%s %s: %s %s (namespace: %s)\n", symbols[c.Action], c.Kind, c.Action, c.Path, ns)
+		} else {
+			fmt.Fprintf(w, "%s %s %s: %s %s (namespace: %s)\n", symbols[c.Action], c.Kind, c.File, c.Action, c.Path, ns)
+		}
+		for _, d := range c.Diffs {
+			if c.Sensitive {
+				fmt.Fprintf(w, "      %s: (sensitive value)\n", d.Field)
+				continue
+			}
+			if c.Action == plan.ActionCreate {
+				fmt.Fprintf(w, "      %s: %s\n", d.Field, formatValue(d.New))
+				continue
+			}
+			fmt.Fprintf(w, "      %s: %s => %s\n", d.Field, formatValue(d.Old), formatValue(d.New))
+		}
+	}
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	switch t := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,458 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/events"
+	"github.com/ibm/vault-cli/pkg/owner"
+	"github.com/ibm/vault-cli/pkg/plan"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+	"github.com/posener/complete"
+)
+
+// protectedNames are never pruned, whether the inventory declares them or not
+var protectedNames = map[string]bool{
+	"root":       true,
+	"default":    true,
+	"token/":     true,
+	"sys/":       true,
+	"identity/":  true,
+	"cubbyhole/": true,
+}
+
+// pruneOrder is the order kinds are deleted in, the reverse of apply
+var pruneOrder = map[string]int{
+	"pkirole":        0,
+	"sshrole":        0,
+	"jwtrole":        0,
+	"vaultrole":      0,
+	"vaultpolicy":    1,
+	"vaultauth":      2,
+	"vaultendpoint":  3,
+	"vaultnamespace": 4,
+}
+
+type PruneCommand struct {
+	Meta                Meta
+	FlagAutoApprove     bool
+	FlagContinueOnError bool
+}
+
+// pruneEntry is a name found in a listing, with the description of mounts
+type pruneEntry struct {
+	Name        string
+	Description string
+}
+
+// pruneListing is a vault path whose entries are compared with the inventory.
+// Mounts are read from the sys/auth and sys/mounts tables, everything else is
+// listed. The delete path of an entry is Prefix followed by the entry name.
+type pruneListing struct {
+	Kind      string
+	Namespace string
+	Path      string
+	Prefix    string
+	Mounts    bool
+}
+
+func (c *PruneCommand) Help() string {
+	helpText := `
+Usage: vault-cli prune [options]
+
+  Deletes the vault objects that no file in the context inventory declares.
+  Only the paths the inventory manages are compared: sys/namespaces,
+  sys/mounts, sys/auth and sys/policy in the namespaces holding an inventory
+  file of that kind, auth/<path>/role of every vaultauth and <path>/roles of
+  every pki and ssh vaultendpoint. The whole inventory is always read, no
+  filespec is accepted.
+
+  Only objects vault-cli put from this context are deleted: mounts and auth
+  methods whose description carries the context ownership marker and
+  objects recorded for the context in the ownership index (see "vault-cli
+  owned -h"). Other undeclared objects are listed as skipped. The objects
+  root, default, token/, sys/, identity/ and cubbyhole/ are never deleted.
+  The deletes are listed and must be confirmed before anything is deleted.
+
+Prune Options:
+  -auto-approve
+    Delete without asking for confirmation.
+
+  -continue-on-error
+    Keep deleting the remaining objects when one fails. By default prune
+    stops at the first failure.
+
+  ` + eventOptionsUsage() + `
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PruneCommand) AutocompleteFlags() complete.Flags {
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(), complete.Flags{
+		"-auto-approve":      complete.PredictNothing,
+		"-continue-on-error": complete.PredictNothing,
+	})
+}
+
+func (c *PruneCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *PruneCommand) Synopsis() string {
+	return "prune deletes vault objects that are not in the inventory"
+}
+
+func (c *PruneCommand) Name() string { return "prune" }
+
+func (c *PruneCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagAutoApprove, "auto-approve", false, "")
+	flagSet.BoolVar(&c.FlagContinueOnError, "continue-on-error", false, "")
+	c.Meta.eventFlags(flagSet)
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(flagSet.Args()) > 0 {
+		fmt.Fprintf(os.Stderr, "prune takes no arguments, it always compares the whole inventory\n")
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+	defer c.Meta.closeEvents()
+
+	apply := &ApplyCommand{Meta: c.Meta, FlagContinueOnError: c.FlagContinueOnError}
+	report := apply.prune(c.FlagAutoApprove)
+	err = c.Meta.output(report, func(w io.Writer) error {
+		report.printSummary(w)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if !report.ok() {
+		return 1
+	}
+	return 0
+}
+
+// pruneResult is what prune did with one object it compared
+type pruneResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// pruneReport is the result prune prints. Objects are deleted, failed,
+// skipped when vault-cli does not manage them or planned when the prune was
+// cancelled or stopped at a failure.
+type pruneReport struct {
+	Objects   []pruneResult `json:"objects"`
+	Deleted   int           `json:"deleted"`
+	Failed    int           `json:"failed"`
+	Cancelled bool          `json:"cancelled,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func (r *pruneReport) ok() bool {
+	return r.Failed == 0 && !r.Cancelled && r.Error == ""
+}
+
+func (r *pruneReport) printSummary(w io.Writer) {
+	switch {
+	case r.Error != "":
+		fmt.Fprintf(w, "%s\n", r.Error)
+	case r.Cancelled:
+		fmt.Fprintf(w, "Prune cancelled\n")
+	case r.Deleted+r.Failed == 0:
+		fmt.Fprintf(w, "Prune: nothing to delete\n")
+	default:
+		fmt.Fprintf(w, "Prune complete: %d deleted, %d failed\n", r.Deleted, r.Failed)
+	}
+}
+
+// prune lists the objects to delete, asks for confirmation unless
+// autoApprove is set and deletes them
+func (c *ApplyCommand) prune(autoApprove bool) *pruneReport {
+	report := &pruneReport{Objects: []pruneResult{}}
+	deletes, skipped, err := c.planPrune()
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	for _, d := range skipped {
+		report.Objects = append(report.Objects, pruneResult{Kind: d.Kind, Namespace: d.Namespace, Path: d.Path, Status: "skipped"})
+		c.Meta.infof("  %s: %s not managed by vault-cli, skipped (namespace: %s)\n", d.Kind, d.Path, d.Namespace)
+	}
+	if len(deletes) == 0 {
+		return report
+	}
+
+	printChanges(c.Meta.infoWriter(), deletes)
+	if !autoApprove {
+		if c.Meta.printer.Structured() {
+			report.Error = fmt.Sprintf("prune cannot ask for confirmation with -output %s, use -auto-approve", c.Meta.outputFormat)
+			return report
+		}
+		answer, err := c.Meta.Ui.Ask(fmt.Sprintf("Delete %d objects? Only 'yes' will be accepted:", len(deletes)))
+		if err != nil || answer != "yes" {
+			report.Cancelled = true
+			return report
+		}
+	}
+
+	for i, d := range deletes {
+		result := pruneResult{Kind: d.Kind, Namespace: d.Namespace, Path: d.Path, Status: "deleted"}
+		c.Meta.SecretService.GetClient().SetNamespace(d.Namespace)
+		start := time.Now()
+		_, err := c.Meta.SecretService.Delete(d.Path)
+		e := &events.Event{
+			Time:      start.UTC(),
+			Kind:      d.Kind,
+			Namespace: d.Namespace,
+			Path:      d.Path,
+			Action:    events.ActionDeleted,
+			Duration:  time.Since(start).Seconds(),
+		}
+		if err != nil {
+			e.Action, e.Error = events.ActionFailed, err.Error()
+		}
+		c.Meta.record(e)
+		if err != nil {
+			report.Failed++
+			result.Status, result.Error = "failed", err.Error()
+			report.Objects = append(report.Objects, result)
+			c.Meta.infof("%s: %s %s\n", d.Kind, d.Path, err)
+			if !c.FlagContinueOnError {
+				for _, rest := range deletes[i+1:] {
+					report.Objects = append(report.Objects, pruneResult{Kind: rest.Kind, Namespace: rest.Namespace, Path: rest.Path, Status: "planned"})
+				}
+				break
+			}
+			continue
+		}
+		report.Deleted++
+		report.Objects = append(report.Objects, result)
+		if c.Meta.Owners != nil {
+			if err := c.Meta.Owners.Delete(d.Namespace, d.Path); err != nil {
+				c.Meta.infof("%s: %s unable to remove from ownership index: %s\n", d.Kind, d.Path, err)
+			}
+		}
+	}
+	return report
+}
+
+// planPrune returns a delete for every entry of the managed listings that
+// the inventory does not declare and this context owns, in the order they
+// must be deleted, and the undeclared entries it does not own
+func (c *ApplyCommand) planPrune() ([]*plan.Change, []*plan.Change, error) {
+	declared, listings, err := c.managedObjects()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deletes := []*plan.Change{}
+	skipped := []*plan.Change{}
+	for _, l := range listings {
+		entries, err := c.listEntries(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: unable to list %s: %s", l.Kind, l.Path, err)
+		}
+		for _, e := range entries {
+			if protectedNames[e.Name] || protectedNames[strings.TrimSuffix(e.Name, "/")] {
+				continue
+			}
+			d := plan.NewDelete(l.Kind, l.Namespace, l.Prefix+strings.TrimSuffix(e.Name, "/"))
+			if declared[d.Key()] {
+				continue
+			}
+			owned, err := c.owned(d, e.Description)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !owned {
+				skipped = append(skipped, d)
+				continue
+			}
+			deletes = append(deletes, d)
+		}
+	}
+
+	sort.SliceStable(deletes, func(i, j int) bool {
+		if pruneOrder[deletes[i].Kind] != pruneOrder[deletes[j].Kind] {
+			return pruneOrder[deletes[i].Kind] < pruneOrder[deletes[j].Kind]
+		}
+		if deletes[i].Kind == "vaultnamespace" {
+			return pruneDepth(deletes[i]) > pruneDepth(deletes[j])
+		}
+		return false
+	})
+	return deletes, skipped, nil
+}
+
+// owned reports whether the object to delete was put from this context,
+// going by the description marker of mounts or the ownership index
+func (c *ApplyCommand) owned(d *plan.Change, description string) (bool, error) {
+	if ctx, _, ok := owner.Parse(description); ok {
+		return ctx == c.Meta.CurrentContext.Name, nil
+	}
+	if c.Meta.Owners == nil {
+		return false, nil
+	}
+	r, err := c.Meta.Owners.Get(d.Namespace, d.Path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read ownership index: %s", err)
+	}
+	return r.Owns(c.Meta.CurrentContext.Name), nil
+}
+
+// pruneDepth is the depth of a namespace delete, so children go first
+func pruneDepth(d *plan.Change) int {
+	return namespaceDepth(d.Namespace, strings.TrimPrefix(d.Path, "sys/namespaces/"))
+}
+
+// managedObjects renders every inventory file and returns the keys of the
+// objects they declare and the listings holding objects of the same kinds
+func (c *ApplyCommand) managedObjects() (map[string]bool, []pruneListing, error) {
+	declared := map[string]bool{}
+	declare := func(kind, namespace, path string) {
+		declared[plan.NewDelete(kind, namespace, strings.TrimPrefix(path, "/")).Key()] = true
+	}
+	listings := []pruneListing{}
+	listed := map[string]bool{}
+	list := func(l pruneListing) {
+		if !listed[l.Namespace+"|"+l.Path] {
+			listed[l.Namespace+"|"+l.Path] = true
+			listings = append(listings, l)
+		}
+	}
+
+	changes := map[string]func(f string) (*plan.Change, error){
+		"vaultpolicy": (&PutVaultPolicyCommand{Meta: c.Meta}).change,
+		"vaultrole":   (&PutVaultRoleCommand{Meta: c.Meta}).change,
+		"jwtrole":     (&PutJWTRoleCommand{Meta: c.Meta}).change,
+		"pkirole":     (&PutPKIRoleCommand{Meta: c.Meta}).change,
+		"sshrole":     (&PutSSHRoleCommand{Meta: c.Meta}).change,
+	}
+	namespaces := &PutVaultNamespaceCommand{Meta: c.Meta}
+
+	// namespaces and auth methods with no namespace are put in the
+	// namespace of the context
+	defaultNamespace := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	namespaceOrDefault := func(ns string) string {
+		if ns == "" {
+			return defaultNamespace
+		}
+		return ns
+	}
+
+	for _, dir := range []string{"vaultnamespace", "vaultendpoint", "vaultauth", "vaultpolicy", "vaultrole", "jwtrole", "pkirole", "sshrole"} {
+		files, err := c.inventoryFiles(applyKind{Dir: dir}, "*")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range files {
+			switch dir {
+			case "vaultnamespace":
+				ns, err := namespaces.Render(f)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: (%s) %s", dir, f, err)
+				}
+				name := strings.Split(strings.Trim(ns.Spec.NamespaceName, "/"), "/")[0]
+				base := namespaceOrDefault(ns.Spec.NamespaceBase)
+				declare(dir, base, "sys/namespaces/"+name)
+				list(pruneListing{Kind: dir, Namespace: base, Path: "sys/namespaces", Prefix: "sys/namespaces/"})
+
+			case "vaultendpoint":
+				endpoint := vaultapi.VaultEndpoint{}
+				if err := c.Meta.renderInventoryFile(dir, f, "VaultEndpoint", &endpoint); err != nil {
+					return nil, nil, fmt.Errorf("%s: (%s) %s", dir, f, err)
+				}
+				ns := endpoint.Spec.VaultNamespace
+				path := strings.Trim(endpoint.Spec.Path, "/")
+				declare(dir, ns, "sys/mounts/"+path)
+				list(pruneListing{Kind: dir, Namespace: ns, Path: "sys/mounts", Prefix: "sys/mounts/", Mounts: true})
+				switch endpoint.Spec.MountOptions.Type {
+				case "pki":
+					list(pruneListing{Kind: "pkirole", Namespace: ns, Path: path + "/roles", Prefix: path + "/roles/"})
+				case "ssh":
+					list(pruneListing{Kind: "sshrole", Namespace: ns, Path: path + "/roles", Prefix: path + "/roles/"})
+				}
+
+			case "vaultauth":
+				auth := vaultapi.VaultAuth{}
+				if err := c.Meta.renderInventoryFile(dir, f, "VaultAuth", &auth); err != nil {
+					return nil, nil, fmt.Errorf("%s: (%s) %s", dir, f, err)
+				}
+				ns := namespaceOrDefault(auth.Spec.VaultNamespace)
+				path := strings.Trim(auth.Spec.Path, "/")
+				declare(dir, ns, "sys/auth/"+path)
+				list(pruneListing{Kind: dir, Namespace: ns, Path: "sys/auth", Prefix: "sys/auth/", Mounts: true})
+				roleKind := "vaultrole"
+				if auth.Spec.Data.Type == "jwt" || auth.Spec.Data.Type == "oidc" {
+					roleKind = "jwtrole"
+				}
+				list(pruneListing{Kind: roleKind, Namespace: ns, Path: "auth/" + path + "/role", Prefix: "auth/" + path + "/role/"})
+
+			default:
+				change, err := changes[dir](f)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: (%s) %s", dir, f, err)
+				}
+				declare(dir, change.Namespace, change.Path)
+				if dir == "vaultpolicy" {
+					list(pruneListing{Kind: dir, Namespace: change.Namespace, Path: "sys/policy", Prefix: "sys/policy/"})
+				}
+			}
+		}
+	}
+	return declared, listings, nil
+}
+
+// listEntries returns the names vault holds under the listing path
+func (c *ApplyCommand) listEntries(l pruneListing) ([]pruneEntry, error) {
+	c.Meta.SecretService.GetClient().SetNamespace(l.Namespace)
+	entries := []pruneEntry{}
+	if l.Mounts {
+		secret, err := c.Meta.SecretService.Read(l.Path)
+		if err != nil || secret == nil {
+			return entries, err
+		}
+		for k, v := range secret.Data {
+			if mount, ok := v.(map[string]interface{}); ok {
+				description, _ := mount["description"].(string)
+				entries = append(entries, pruneEntry{Name: k, Description: description})
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+		return entries, nil
+	}
+	secret, err := c.Meta.SecretService.List(l.Path)
+	if err != nil || secret == nil {
+		return entries, err
+	}
+	keys, _ := secret.Data["keys"].([]interface{})
+	for _, k := range keys {
+		entries = append(entries, pruneEntry{Name: fmt.Sprint(k)})
+	}
+	return entries, nil
+}
@@ -1,6 +1,8 @@
 package command
 
 import (
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/mitchellh/cli"
@@ -32,3 +34,33 @@ func (f *PutCommand) Name() string { return "Put" }
 func (f *PutCommand) Run(args []string) int {
 	return cli.RunResultHelp
 }
+
+// putResult is the outcome of the put of one inventory file
+type putResult struct {
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Namespace string `json:"namespace,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Action    string `json:"action"`
+	Error     string `json:"error,omitempty"`
+}
+
+// putReport is the result the put commands print
+type putReport struct {
+	Resources []putResult `json:"resources"`
+}
+
+// printPuts prints the results of the puts of the command and returns the
+// exit code. The text form is the progress putFile printed already, a dry
+// run puts nothing and printed its changes.
+func (m *Meta) printPuts() int {
+	if len(m.puts) == 0 {
+		return 0
+	}
+	err := m.output(&putReport{Resources: m.puts}, func(w io.Writer) error { return nil })
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -7,19 +7,26 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutPKIRoleCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagDryRun bool
 }
 
 func (c *PutPKIRoleCommand) Help() string {
 	helpText := `
-Usage: vault-cli put pkirole [options]
+Usage: vault-cli put pkirole [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -28,8 +35,10 @@ General Options:
 }
 
 func (c *PutPKIRoleCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutPKIRoleCommand) AutocompleteArgs() complete.Predictor {
@@ -47,6 +56,8 @@ func (c *PutPKIRoleCommand) Run(args []string) int {
 	// get the flags specific to this command
 
 	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -62,6 +73,7 @@ func (c *PutPKIRoleCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/pkirole/", filespec)
 	if err != nil {
@@ -74,40 +86,60 @@ func (c *PutPKIRoleCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/pkirole/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
-		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("JWTRole", data, c.Meta.flagData)
-		if err != nil {
-			fmt.Printf("unable to apply template to jwtrole: %s\n", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("pkirole", f, c.Put, c.Plan)
 		}
-		pkirole := vaultapi.PKIRole{}
-		err = yaml.Unmarshal(yamlbytes, &pkirole)
 		if err != nil {
-			fmt.Printf("unable to marshal pkirole: %s\n", err.Error())
+			c.Meta.infof("(%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		c.Meta.SecretService.GetClient().SetNamespace(pkirole.Spec.VaultNamespace)
+	return c.Meta.printPuts()
+}
 
-		pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+// Put renders the pkirole inventory file f and writes the role to vault
+func (c *PutPKIRoleCommand) Put(f string) error {
+	change, err := c.change(f)
+	if err != nil {
+		return err
+	}
+	return c.Meta.writeChange(change)
+}
 
-		// unmarshal the Role Options
-		data, err = pkiiter.Marshal(pkirole.Spec.Config)
-		m := make(map[string]interface{})
-		json.Unmarshal(data, &m)
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("/%s/roles/%s", pkirole.Spec.IssuerPath, pkirole.Spec.RoleName), m)
-		if err != nil {
-			//fmt.Printf(err.Error())
-			fmt.Printf("(%s) %s", f, err)
-			return 1
-		}
-		fmt.Printf("PKI Role (%s) write OK\n", f)
+// Plan compares the pkirole inventory file f with the role in vault
+func (c *PutPKIRoleCommand) Plan(f string) ([]*plan.Change, error) {
+	change, err := c.change(f)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Meta.planChange(change)
+	if err != nil {
+		return nil, err
+	}
+	return []*plan.Change{change}, nil
+}
+
+// change renders the pkirole inventory file f into the role write
+func (c *PutPKIRoleCommand) change(f string) (*plan.Change, error) {
+	pkirole := vaultapi.PKIRole{}
+	err := c.Meta.renderInventoryFile("pkirole", f, "PKIRole", &pkirole)
+	if err != nil {
+		return nil, err
 	}
 
-	return 0
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+
+	// unmarshal the Role Options
+	data, err := pkiiter.Marshal(pkirole.Spec.Config)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	path := fmt.Sprintf("/%s/roles/%s", pkirole.Spec.IssuerPath, pkirole.Spec.RoleName)
+	return plan.NewChange("pkirole", f, pkirole.Spec.VaultNamespace, path, m), nil
 }
@@ -1,27 +1,37 @@
 package command
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/vault/api"
 	pkgargs "github.com/ibm/vault-cli/pkg/args"
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/output"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutSecretCommand struct {
-	Meta  Meta
-	ioDir string
+	Meta       Meta
+	ioDir      string
+	FlagDryRun bool
 }
 
 func (c *PutSecretCommand) Help() string {
 	helpText := `
-Usage: vault-cli put secretmeta [options]
+Usage: vault-cli put secretmeta [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -30,8 +40,10 @@ General Options:
 }
 
 func (c *PutSecretCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutSecretCommand) AutocompleteArgs() complete.Predictor {
@@ -50,6 +62,8 @@ func (c *PutSecretCommand) Run(args []string) int {
 
 	flagSet := c.Meta.FlagSet(c.Name())
 	flagSet.StringVar(&c.ioDir, "dir", "", "")
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -65,6 +79,7 @@ func (c *PutSecretCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/secretmeta/", filespec)
 	if err != nil {
@@ -77,92 +92,174 @@ func (c *PutSecretCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/secretmeta/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
-		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("Secret", data, c.Meta.flagData)
-		if err != nil {
-			fmt.Printf("unable to apply template to secretmeta: %s\n", err.Error())
-			return 1
-		}
-		secretmeta := vaultapi.SecretMeta{}
-		err = yaml.Unmarshal(yamlbytes, &secretmeta)
-		if err != nil {
-			fmt.Printf("unable to marshal secretmeta: %s\n", err.Error())
-			return 1
-		}
-		if secretmeta.Spec.Type != "kv-v2" {
-			fmt.Printf("secret type must be kv-v2\n")
-			return 1
+		// the key values are read once, a value from stdin can only be
+		// read once, and planned and written alike
+		change, changeErr := c.change(f, args[1:])
+		planFn := func(f string) ([]*plan.Change, error) {
+			if changeErr != nil {
+				return nil, changeErr
+			}
+			return c.planWrite(change)
 		}
-		path := secretmeta.Spec.KVPath.Path
-		if c.ioDir != "" {
-			for _, key := range secretmeta.Spec.KVPath.Keys {
-				filename := c.ioDir + string(os.PathSeparator) + key.Name
-				if _, err := os.Stat(filename); err == nil {
-					args = append(args, key.Name+"=@"+filename)
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, planFn)
+		} else {
+			var secret *api.Secret
+			_, err = c.Meta.putFile("secretmeta", f, func(f string) error {
+				if changeErr != nil {
+					return changeErr
 				}
+				secret, err = c.write(change)
+				return err
+			}, planFn)
+			if err == nil && secret != nil {
+				err = c.Meta.output(secret, func(w io.Writer) error {
+					keys := []string{}
+					for k := range secret.Data {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					rows := [][]string{}
+					for _, k := range keys {
+						rows = append(rows, []string{k, fmt.Sprint(secret.Data[k])})
+					}
+					return output.Table(w, []string{"KEY", "VALUE"}, rows)
+				})
 			}
 		}
-		// Pull our fake stdin if needed
-		stdin := (io.Reader)(os.Stdin)
-		argArray, err := pkgargs.ParseArgsData(stdin, args[1:])
 		if err != nil {
-			fmt.Printf("Failed to parse K=V argArray: %s\n", err)
+			fmt.Printf("%s\n", err)
 			return 1
 		}
-		// all keys defined in secretmeta must be present
-		for _, k := range secretmeta.Spec.KVPath.Keys {
-			if argArray[k.Name] == nil {
-				fmt.Printf("required key not defined (key: %s)\n", k.Name)
-				return 1
+	}
+
+	return 0
+}
+
+// Put renders the secretmeta inventory file f and writes the key values
+// given in kvArgs (and any files found in -dir) to the kv-v2 path. It
+// returns the response of the write, the version metadata for kv-v2.
+func (c *PutSecretCommand) Put(f string, kvArgs []string) (*api.Secret, error) {
+	change, err := c.change(f, kvArgs)
+	if err != nil {
+		return nil, err
+	}
+	return c.write(change)
+}
+
+// write writes the key values of change and stamps their ownership
+func (c *PutSecretCommand) write(change *plan.Change) (*api.Secret, error) {
+	secret, err := c.Meta.SecretService.Write(change.Path, change.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Error writing data to %s: %s", change.Path, err)
+	}
+	err = c.stamp(change.File, change.Path)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// stamp sets the vault-cli ownership custom metadata on the kv-v2 secret
+// written to path. kv version 1 secrets have no metadata.
+func (c *PutSecretCommand) stamp(f, path string) error {
+	mountPath, v2, err := c.Meta.SecretService.IsKVv2(path)
+	if err != nil || !v2 {
+		return err
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), mountPath+"data/")
+	metadataPath := pkgargs.AddPrefixToVKVPath(mountPath+path, mountPath, "metadata")
+	_, err = c.Meta.SecretService.Write(metadataPath, map[string]interface{}{
+		"custom_metadata": map[string]interface{}{
+			"vault-cli-context": c.Meta.CurrentContext.Name,
+			"vault-cli-file":    "secretmeta/" + f,
+			"vault-cli-applied": time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set ownership metadata on %s: %s", metadataPath, err)
+	}
+	return nil
+}
+
+// Plan compares the key values for the secretmeta inventory file f with the
+// secret in vault. Values are never printed.
+func (c *PutSecretCommand) Plan(f string, kvArgs []string) ([]*plan.Change, error) {
+	change, err := c.change(f, kvArgs)
+	if err != nil {
+		return nil, err
+	}
+	return c.planWrite(change)
+}
+
+// planWrite compares the key values of change with the secret in vault
+func (c *PutSecretCommand) planWrite(change *plan.Change) ([]*plan.Change, error) {
+	err := c.Meta.planChange(change)
+	if err != nil {
+		return nil, err
+	}
+	return []*plan.Change{change}, nil
+}
+
+// change validates the key values for the secretmeta inventory file f and
+// returns the write to the kv path
+func (c *PutSecretCommand) change(f string, kvArgs []string) (*plan.Change, error) {
+	secretmeta := vaultapi.SecretMeta{}
+	err := c.Meta.renderInventoryFile("secretmeta", f, "Secret", &secretmeta)
+	if err != nil {
+		return nil, err
+	}
+	if secretmeta.Spec.Type != "kv-v2" {
+		return nil, fmt.Errorf("secret type must be kv-v2")
+	}
+	path := secretmeta.Spec.KVPath.Path
+	if c.ioDir != "" {
+		for _, key := range secretmeta.Spec.KVPath.Keys {
+			filename := c.ioDir + string(os.PathSeparator) + key.Name
+			if _, err := os.Stat(filename); err == nil {
+				kvArgs = append(kvArgs, key.Name+"=@"+filename)
 			}
 		}
-		// look for unknown or misspelled key name
-		for k := range argArray {
-			if _, ok := GetKeyFromKVKeysByName(secretmeta.Spec.KVPath.Keys, k); !ok {
-				fmt.Printf("unknown key provided (key: %s)\n", k)
-				return 1
-			}
+	}
+	// Pull our fake stdin if needed
+	stdin := (io.Reader)(os.Stdin)
+	argArray, err := pkgargs.ParseArgsData(stdin, kvArgs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse K=V argArray: %s", err)
+	}
+	// all keys defined in secretmeta must be present
+	for _, k := range secretmeta.Spec.KVPath.Keys {
+		if argArray[k.Name] == nil {
+			return nil, fmt.Errorf("required key not defined (key: %s)", k.Name)
 		}
-		mountPath, v2, err := c.Meta.SecretService.IsKVv2(path)
-		if err != nil {
-			fmt.Printf("error:%s\n", err.Error())
-			return 1
+	}
+	// look for unknown or misspelled key name
+	for k := range argArray {
+		if _, ok := GetKeyFromKVKeysByName(secretmeta.Spec.KVPath.Keys, k); !ok {
+			return nil, fmt.Errorf("unknown key provided (key: %s)", k)
 		}
+	}
+	mountPath, v2, err := c.Meta.SecretService.IsKVv2(path)
+	if err != nil {
+		return nil, fmt.Errorf("error:%s", err.Error())
+	}
 
-		if v2 {
-			path = pkgargs.AddPrefixToVKVPath(path, mountPath, "data")
-			//path = mountPath + "data" + path
-			argArray = map[string]interface{}{
-				"data":    argArray,
-				"options": map[string]interface{}{},
-			}
-
-			// if c.flagCAS > -1 {
-			// 	data["options"].(map[string]interface{})["cas"] = c.flagCAS
-			// }
-		}
-		secret, err := c.Meta.SecretService.Write(path, argArray)
-		if err != nil {
-			fmt.Printf("Error writing data to %s: %s\n", path, err)
-			return 1
-		}
-		if secret != nil {
-			out, err := json.MarshalIndent(secret, "", "  ")
-			if err != nil {
-				fmt.Printf("error marshaling secret :%s\n", err.Error())
-				return 1
-			}
-			fmt.Println(string(out))
+	if v2 {
+		path = pkgargs.AddPrefixToVKVPath(path, mountPath, "data")
+		//path = mountPath + "data" + path
+		argArray = map[string]interface{}{
+			"data":    argArray,
+			"options": map[string]interface{}{},
 		}
 
+		// if c.flagCAS > -1 {
+		// 	data["options"].(map[string]interface{})["cas"] = c.flagCAS
+		// }
 	}
-
-	return 0
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	change := plan.NewChange("secretmeta", f, ns, path, argArray)
+	change.Sensitive = true
+	return change, nil
 }
 
 // GetKeyFromKVKeysByName searches kvKey array for a key with the name
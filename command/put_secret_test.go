@@ -0,0 +1,78 @@
+package command_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+const putSecretMeta = `apiVersion: api.gensec.ibm.com/v1
+kind: SecretMeta
+metadata:
+  name: password
+spec:
+  type: kv-v2
+  kvPath:
+    keys:
+    - name: password
+    path: demo/password
+`
+
+func TestPutSecretFromStdin(t *testing.T) {
+	var mu sync.Mutex
+	written := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.URL.Path == "/v1/sys/internal/ui/mounts/demo/password":
+			w.Write([]byte(`{"data":{"path":"demo/","options":{"version":"2"}}}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		case r.URL.Path == "/v1/demo/data/password":
+			body := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %s", err)
+			}
+			mu.Lock()
+			written = append(written, body)
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"version":1}}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"secretmeta/password.yaml": putSecretMeta})
+	defer os.RemoveAll(dir)
+
+	// the value is read from os.Stdin
+	stdin := os.Stdin
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.Stdin = stdin }()
+	os.Stdin = r
+	w.Write([]byte("s3cret"))
+	w.Close()
+
+	code, _, errors := runCommand(t, "put secret", "-config", configPath, "-o", "json", "password", "password=-")
+	if code != 0 {
+		t.Fatalf("put failed with %d: %s", code, errors)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(written) != 1 {
+		t.Fatalf("expected one write, got %v", written)
+	}
+	if data, _ := written[0]["data"].(map[string]interface{}); data["password"] != "s3cret" {
+		t.Errorf("expected the value of stdin to be written, got %v", written[0])
+	}
+}
@@ -7,19 +7,26 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutSSHRoleCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagDryRun bool
 }
 
 func (c *PutSSHRoleCommand) Help() string {
 	helpText := `
-Usage: vault-cli put sshrole [options]
+Usage: vault-cli put sshrole [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -28,8 +35,10 @@ General Options:
 }
 
 func (c *PutSSHRoleCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutSSHRoleCommand) AutocompleteArgs() complete.Predictor {
@@ -47,6 +56,8 @@ func (c *PutSSHRoleCommand) Run(args []string) int {
 	// get the flags specific to this command
 
 	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -62,6 +73,7 @@ func (c *PutSSHRoleCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/sshrole/", filespec)
 	if err != nil {
@@ -74,43 +86,63 @@ func (c *PutSSHRoleCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/sshrole/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
-		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("SSHRole", data, c.Meta.flagData)
-		if err != nil {
-			fmt.Printf("unable to apply template to sshrole: %s\n", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("sshrole", f, c.Put, c.Plan)
 		}
-		sshrole := vaultapi.SSHRole{}
-		err = yaml.Unmarshal(yamlbytes, &sshrole)
 		if err != nil {
-			fmt.Printf("unable to marshal sshrole: %s\n", err.Error())
+			c.Meta.infof("(%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		c.Meta.SecretService.GetClient().SetNamespace(sshrole.Spec.VaultNamespace)
-
-		name := sshrole.Spec.RoleName
-		signerPath := sshrole.Spec.SignerPath
+	return c.Meta.printPuts()
+}
 
-		sshiter := jsoniter.Config{TagKey: "vault"}.Froze()
+// Put renders the sshrole inventory file f and writes the role to vault
+func (c *PutSSHRoleCommand) Put(f string) error {
+	change, err := c.change(f)
+	if err != nil {
+		return err
+	}
+	return c.Meta.writeChange(change)
+}
 
-		// unmarshal the Role Options
-		data, err = sshiter.Marshal(sshrole.Spec.Parameters)
-		m := make(map[string]interface{})
-		json.Unmarshal(data, &m)
+// Plan compares the sshrole inventory file f with the role in vault
+func (c *PutSSHRoleCommand) Plan(f string) ([]*plan.Change, error) {
+	change, err := c.change(f)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Meta.planChange(change)
+	if err != nil {
+		return nil, err
+	}
+	return []*plan.Change{change}, nil
+}
 
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("/%s/roles/%s", signerPath, name), m)
-		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
-			return 1
-		}
-		fmt.Printf("SSH Role (%s) write OK\n", f)
+// change renders the sshrole inventory file f into the role write
+func (c *PutSSHRoleCommand) change(f string) (*plan.Change, error) {
+	sshrole := vaultapi.SSHRole{}
+	err := c.Meta.renderInventoryFile("sshrole", f, "SSHRole", &sshrole)
+	if err != nil {
+		return nil, err
 	}
 
-	return 0
+	name := sshrole.Spec.RoleName
+	signerPath := sshrole.Spec.SignerPath
+
+	sshiter := jsoniter.Config{TagKey: "vault"}.Froze()
+
+	// unmarshal the Role Options
+	data, err := sshiter.Marshal(sshrole.Spec.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	path := fmt.Sprintf("/%s/roles/%s", signerPath, name)
+	return plan.NewChange("sshrole", f, sshrole.Spec.VaultNamespace, path, m), nil
 }
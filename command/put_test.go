@@ -0,0 +1,99 @@
+package command_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPutOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"vaultpolicy/operator.yaml": applyPolicy})
+	defer os.RemoveAll(dir)
+
+	code, lines, errors := runCommand(t, "put vaultpolicy", "-config", configPath, "-o", "json", "operator")
+	if code != 0 {
+		t.Fatalf("put failed with %d: %s", code, errors)
+	}
+	var report struct {
+		Resources []map[string]string `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(strings.Join(lines, "\n")), &report); err != nil {
+		t.Fatalf("expected a json document on stdout, got %q: %s", lines, err)
+	}
+	expected := map[string]string{"kind": "vaultpolicy", "file": "operator", "path": "sys/policy/operator", "action": "created"}
+	if len(report.Resources) != 1 {
+		t.Fatalf("expected one result, got %v", report.Resources)
+	}
+	for k, v := range expected {
+		if report.Resources[0][k] != v {
+			t.Errorf("expected %s %q, got %v", k, v, report.Resources[0])
+		}
+	}
+}
+
+const putEndpoint = `apiVersion: api.gensec.ibm.com/v1
+kind: VaultEndpoint
+metadata:
+  name: demo
+spec:
+  vaultNamespace: root
+  path: demo
+  mountOptions:
+    type: kv-v2
+`
+
+func TestPutEndpointMountError(t *testing.T) {
+	tuned := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/sys/mounts/demo/tune":
+			// vault answers a tune read of a path not mounted with 400
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["cannot fetch sysview for path \"demo/\""]}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		case r.URL.Path == "/v1/sys/mounts/demo":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["path is already in use"]}`))
+		case r.URL.Path == "/v1/sys/mounts/demo/tune":
+			tuned = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	dir, configPath := inventoryConfig(t, server.URL, map[string]string{"vaultendpoint/demo.yaml": putEndpoint})
+	defer os.RemoveAll(dir)
+
+	code, lines, _ := runCommand(t, "put vaultendpoint", "-config", configPath, "-o", "json", "demo")
+	if code != 1 {
+		t.Fatalf("expected the failed mount to fail the put, got %d", code)
+	}
+	if tuned {
+		t.Errorf("expected no tune of a failed mount")
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), `"action": "failed"`) {
+		t.Errorf("expected a failed result, got %q", lines)
+	}
+}
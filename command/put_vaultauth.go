@@ -7,19 +7,26 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutVaultAuthCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagDryRun bool
 }
 
 func (c *PutVaultAuthCommand) Help() string {
 	helpText := `
-Usage: vault-cli put auth [options]
+Usage: vault-cli put auth [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -28,8 +35,10 @@ General Options:
 }
 
 func (c *PutVaultAuthCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutVaultAuthCommand) AutocompleteArgs() complete.Predictor {
@@ -47,6 +56,8 @@ func (c *PutVaultAuthCommand) Run(args []string) int {
 	// get the flags specific to this command
 
 	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -62,6 +73,7 @@ func (c *PutVaultAuthCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/vaultauth/", filespec)
 	if err != nil {
@@ -74,56 +86,133 @@ func (c *PutVaultAuthCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/vaultauth/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
-		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("VaultAuth", data, c.Meta.flagData)
-		if err != nil {
-			fmt.Printf("unable to apply template to vaultauth: %s\n", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("vaultauth", f, c.Put, c.Plan)
 		}
-		vaultAuth := vaultapi.VaultAuth{}
-		err = yaml.Unmarshal(yamlbytes, &vaultAuth)
 		if err != nil {
-			fmt.Printf("unable to marshal vaultauth: %s\n", err.Error())
+			c.Meta.infof("(%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+	return c.Meta.printPuts()
+}
 
-		// unmarshal the mountOptions
-		data, err = pkiiter.Marshal(vaultAuth.Spec.Data)
-		m := make(map[string]interface{})
-		json.Unmarshal(data, &m)
+// Put renders the vaultauth inventory file f and enables or tunes the auth
+// method in vault
+func (c *PutVaultAuthCommand) Put(f string) error {
+	vaultAuth := vaultapi.VaultAuth{}
+	err := c.Meta.renderInventoryFile("vaultauth", f, "VaultAuth", &vaultAuth)
+	if err != nil {
+		return err
+	}
 
-		if vaultAuth.Spec.VaultNamespace != "" {
-			c.Meta.SecretService.GetClient().SetNamespace(vaultAuth.Spec.DeepCopy().VaultNamespace)
-		}
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
 
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/auth/%s", vaultAuth.Spec.Path), m)
-		if err != nil && strings.Contains(err.Error(), "path is already in use") {
-			_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/auth/%s/tune", vaultAuth.Spec.Path), m)
-		}
-		if vaultAuth.Spec.Data.Type == "jwt" {
-			data, err = pkiiter.Marshal(vaultAuth.Spec.JWTConfig)
-			m := make(map[string]interface{})
-			json.Unmarshal(data, &m)
-			_, err = c.Meta.SecretService.Write(fmt.Sprintf("auth/%s/config", vaultAuth.Spec.Path), m)
+	// unmarshal the mountOptions
+	data, err := pkiiter.Marshal(vaultAuth.Spec.Data)
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	m["description"] = c.Meta.ownerMark("vaultauth", f, m["description"])
+
+	if vaultAuth.Spec.VaultNamespace != "" {
+		c.Meta.SecretService.GetClient().SetNamespace(vaultAuth.Spec.DeepCopy().VaultNamespace)
+	}
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+
+	// refuse to take over an auth method put from another inventory file
+	authPath := fmt.Sprintf("sys/auth/%s", vaultAuth.Spec.Path)
+	err = c.Meta.claim("vaultauth", f, ns, authPath)
+	if err != nil {
+		return err
+	}
+	secret, err := c.Meta.SecretService.Read("sys/auth")
+	if err == nil && secret != nil {
+		if current, ok := secret.Data[vaultAuth.Spec.Path+"/"].(map[string]interface{}); ok {
+			description, _ := current["description"].(string)
+			err = c.Meta.claimMarker("vaultauth", f, authPath, description)
 			if err != nil {
-				_, err = c.Meta.SecretService.Write(fmt.Sprintf("auth/%s/config", vaultAuth.Spec.Path), m)
+				return err
 			}
-
 		}
+	}
+
+	_, err = c.Meta.SecretService.Write(authPath, m)
+	if err != nil && strings.Contains(err.Error(), "path is already in use") {
+		_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/auth/%s/tune", vaultAuth.Spec.Path), m)
+	}
+	if vaultAuth.Spec.Data.Type == "jwt" {
+		data, err = pkiiter.Marshal(vaultAuth.Spec.JWTConfig)
+		m := make(map[string]interface{})
+		json.Unmarshal(data, &m)
+		_, err = c.Meta.SecretService.Write(fmt.Sprintf("auth/%s/config", vaultAuth.Spec.Path), m)
 		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
-			return 1
+			_, err = c.Meta.SecretService.Write(fmt.Sprintf("auth/%s/config", vaultAuth.Spec.Path), m)
 		}
-		fmt.Printf("VaultAuth: %s.yaml, Name: %s write OK\n", f, vaultAuth.Spec.Path)
 
 	}
+	if err != nil {
+		return err
+	}
+	return c.Meta.stamp("vaultauth", f, ns, authPath)
+}
 
-	return 0
+// Plan compares the vaultauth inventory file f with the auth method in vault
+func (c *PutVaultAuthCommand) Plan(f string) ([]*plan.Change, error) {
+	vaultAuth := vaultapi.VaultAuth{}
+	err := c.Meta.renderInventoryFile("vaultauth", f, "VaultAuth", &vaultAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+	data, err := pkiiter.Marshal(vaultAuth.Spec.Data)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	m["description"] = c.Meta.ownerMark("vaultauth", f, m["description"])
+
+	if vaultAuth.Spec.VaultNamespace != "" {
+		c.Meta.SecretService.GetClient().SetNamespace(vaultAuth.Spec.VaultNamespace)
+	}
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+
+	// sys/auth lists every enabled method with its type, description and config
+	secret, err := c.Meta.SecretService.Read("sys/auth")
+	if err != nil {
+		return nil, err
+	}
+	var current map[string]interface{}
+	if secret != nil {
+		current, _ = secret.Data[vaultAuth.Spec.Path+"/"].(map[string]interface{})
+	}
+	path := fmt.Sprintf("sys/auth/%s", vaultAuth.Spec.Path)
+	if current != nil {
+		path = fmt.Sprintf("sys/auth/%s/tune", vaultAuth.Spec.Path)
+	}
+	change := plan.NewChange("vaultauth", f, ns, path, m)
+	change.SetCurrent(current)
+	changes := []*plan.Change{change}
+
+	if vaultAuth.Spec.Data.Type == "jwt" {
+		data, err = pkiiter.Marshal(vaultAuth.Spec.JWTConfig)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{})
+		json.Unmarshal(data, &m)
+		change := plan.NewChange("vaultauth", f, ns, fmt.Sprintf("auth/%s/config", vaultAuth.Spec.Path), m)
+		if current == nil {
+			change.SetCurrent(nil)
+		} else if err := c.Meta.planChange(change); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
 }
@@ -7,20 +7,28 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	v1 "github.com/ibm/vault-go/api/v1"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutVaultEndpointCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagForce  bool
+	FlagDryRun bool
 }
 
 func (c *PutVaultEndpointCommand) Help() string {
 	helpText := `
-Usage: vault-cli put endpoint [options]
+Usage: vault-cli put endpoint [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -29,8 +37,9 @@ General Options:
 }
 
 func (c *PutVaultEndpointCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), complete.Flags{
-		"-force": complete.PredictAnything},
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(), complete.Flags{
+		"-dry-run": complete.PredictNothing,
+		"-force":   complete.PredictAnything},
 	)
 }
 
@@ -47,9 +56,10 @@ func (c *PutVaultEndpointCommand) Name() string { return "pki role" }
 func (c *PutVaultEndpointCommand) Run(args []string) int {
 
 	// get the flags specific to this command
-	var putVaultEndpointForce bool
 	flagSet := c.Meta.FlagSet(c.Name())
-	flagSet.BoolVar(&putVaultEndpointForce, "force", false, "")
+	flagSet.BoolVar(&c.FlagForce, "force", false, "")
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -65,6 +75,7 @@ func (c *PutVaultEndpointCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/vaultendpoint/", filespec)
 	if err != nil {
@@ -77,114 +88,230 @@ func (c *PutVaultEndpointCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/vaultendpoint/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("vaultendpoint", f, c.Put, c.Plan)
 		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("VaultEndpoint", data, c.Meta.flagData)
 		if err != nil {
-			fmt.Printf("unable to apply template to vaultendpoint: %s\n", err.Error())
-			return 1
-		}
-		endpoint := vaultapi.VaultEndpoint{}
-		err = yaml.Unmarshal(yamlbytes, &endpoint)
-		if err != nil {
-			fmt.Printf("unable to marshal endpoint: %s\n", err.Error())
+			c.Meta.infof("(%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		c.Meta.SecretService.GetClient().SetNamespace(endpoint.Spec.VaultNamespace)
-		// Mount vaultendpoint options
-		pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+	return c.Meta.printPuts()
+}
 
-		// unmarshal the mountOptions
-		data, err = pkiiter.Marshal(endpoint.Spec.MountOptions)
-		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
-			return 1
-		}
-		m := make(map[string]interface{})
-		json.Unmarshal(data, &m)
+// Put renders the vaultendpoint inventory file f, mounts and tunes the
+// endpoint and configures ssh or pki endpoints the first time they are mounted
+func (c *PutVaultEndpointCommand) Put(f string) error {
+	endpoint := vaultapi.VaultEndpoint{}
+	err := c.Meta.renderInventoryFile("vaultendpoint", f, "VaultEndpoint", &endpoint)
+	if err != nil {
+		return err
+	}
 
-		endpointPreviouslyMounted := true
-		_, err = c.Meta.SecretService.Read(fmt.Sprintf("sys/mounts/%s/tune", endpoint.Spec.Path))
-		if err != nil {
-			endpointPreviouslyMounted = false
-			_, err = c.Meta.SecretService.Write(fmt.Sprintf("/sys/mounts/%s", endpoint.Spec.Path), m)
-			if err != nil {
-				fmt.Printf("(%s) %s", f, err)
-			}
-		}
-		//		if endpoint.Spec.MountOptions.Type != "ssh" {
-		data, err = pkiiter.Marshal(endpoint.Spec.TuneOptions)
-		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
-		}
-		m = make(map[string]interface{})
-		err = json.Unmarshal(data, &m)
+	c.Meta.SecretService.GetClient().SetNamespace(endpoint.Spec.VaultNamespace)
+	// Mount vaultendpoint options
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+
+	// unmarshal the mountOptions
+	data, err := pkiiter.Marshal(endpoint.Spec.MountOptions)
+	if err != nil {
+		return err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	description := c.description(f, &endpoint)
+	m["description"] = description
+
+	// refuse to take over a mount put from another inventory file
+	mountPath := fmt.Sprintf("sys/mounts/%s", endpoint.Spec.Path)
+	err = c.Meta.claim("vaultendpoint", f, endpoint.Spec.VaultNamespace, mountPath)
+	if err != nil {
+		return err
+	}
+
+	endpointPreviouslyMounted := true
+	current, err := c.Meta.SecretService.Read(fmt.Sprintf("sys/mounts/%s/tune", endpoint.Spec.Path))
+	if err != nil {
+		endpointPreviouslyMounted = false
+		_, err = c.Meta.SecretService.Write(fmt.Sprintf("/sys/mounts/%s", endpoint.Spec.Path), m)
 		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
+			return fmt.Errorf("unable to mount %s: %s", endpoint.Spec.Path, err)
 		}
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/mounts/%s/tune", endpoint.Spec.Path), m)
+	} else if current != nil {
+		currentDescription, _ := current.Data["description"].(string)
+		err = c.Meta.claimMarker("vaultendpoint", f, mountPath, currentDescription)
 		if err != nil {
-			fmt.Printf("(%s) %s", f, err)
+			return err
 		}
+	}
+	//		if endpoint.Spec.MountOptions.Type != "ssh" {
+	data, err = pkiiter.Marshal(endpoint.Spec.TuneOptions)
+	if err != nil {
+		return fmt.Errorf("invalid tune options: %s", err)
+	}
+	m = make(map[string]interface{})
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return fmt.Errorf("invalid tune options: %s", err)
+	}
+	m["description"] = description
+	_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/mounts/%s/tune", endpoint.Spec.Path), m)
+	if err != nil {
+		return fmt.Errorf("unable to tune %s: %s", endpoint.Spec.Path, err)
+	}
 
-		//		}
-		if endpoint.Spec.MountOptions.Type == "ssh" {
-			if !endpointPreviouslyMounted {
-				err = c.ConfigureSSHGenerateSigning(f, endpoint.Spec.Path, &endpoint)
-				if err != nil {
-					fmt.Printf("(%s) %s", f, err)
-					return 1
-				}
-				fmt.Printf("SSH Endpoint configured (%s) write OK\n", f)
+	//		}
+	if endpoint.Spec.MountOptions.Type == "ssh" {
+		if !endpointPreviouslyMounted {
+			err = c.ConfigureSSHGenerateSigning(f, endpoint.Spec.Path, &endpoint)
+			if err != nil {
+				return err
 			}
+			c.Meta.infof("vaultendpoint: (%s) ssh signing key generated\n", f)
 		}
+	}
 
-		// START PKI
-		if endpoint.Spec.MountOptions.Type == "pki" {
-			if !endpointPreviouslyMounted || putVaultEndpointForce {
-				if endpoint.Spec.PKIConfig.RootOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) {
-					// TODO handle external Root CA
-					if !endpoint.Spec.PKIConfig.ExportPrivateKey {
-						err = c.ConfigureRootCAInternal(f, endpoint.Spec.Path, &endpoint)
-						if err != nil {
-							fmt.Printf("(%s) %s", f, err)
-							return 1
-						}
-						fmt.Printf("PKI Root configured (%s) write OK\n", f)
-					}
-				}
-				if endpoint.Spec.PKIConfig.IntermediateOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) {
-					// TODO handle external
-					err = c.ConfigureIntermediateCAInternal(f, endpoint.Spec.Path, &endpoint)
+	// START PKI
+	if endpoint.Spec.MountOptions.Type == "pki" {
+		if !endpointPreviouslyMounted || c.FlagForce {
+			if endpoint.Spec.PKIConfig.RootOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) {
+				// TODO handle external Root CA
+				if !endpoint.Spec.PKIConfig.ExportPrivateKey {
+					err = c.ConfigureRootCAInternal(f, endpoint.Spec.Path, &endpoint)
 					if err != nil {
-						fmt.Printf("(%s) %s", f, err)
-						return 1
+						return err
 					}
-					fmt.Printf("PKI Intermediate configured (%s) write OK\n", f)
+					c.Meta.infof("vaultendpoint: (%s) pki root CA generated\n", f)
 				}
-				if endpoint.Spec.PKIConfig.URLs != (*v1.VaultEndpointConfigURLs)(nil) {
-					err = c.ConfigureURLs(f, endpoint.Spec.Path, &endpoint)
-					if err != nil {
-						fmt.Printf("(%s) %s", f, err)
-						return 1
-					}
+			}
+			if endpoint.Spec.PKIConfig.IntermediateOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) {
+				// TODO handle external
+				err = c.ConfigureIntermediateCAInternal(f, endpoint.Spec.Path, &endpoint)
+				if err != nil {
+					return err
+				}
+				c.Meta.infof("vaultendpoint: (%s) pki intermediate CA generated\n", f)
+			}
+			if endpoint.Spec.PKIConfig.URLs != (*v1.VaultEndpointConfigURLs)(nil) {
+				err = c.ConfigureURLs(f, endpoint.Spec.Path, &endpoint)
+				if err != nil {
+					return err
 				}
-				fmt.Printf("PKI Endpoint configured (%s) write OK\n", f)
-			} else {
-				fmt.Printf("PKI Endpoint already configured (%s)  SKIPPING\n", f)
 			}
+			c.Meta.infof("vaultendpoint: (%s) pki configured\n", f)
+		} else {
+			c.Meta.infof("vaultendpoint: (%s) pki already configured, skipped\n", f)
+		}
+	}
+	// End PKI
+	return c.Meta.stamp("vaultendpoint", f, endpoint.Spec.VaultNamespace, mountPath)
+}
+
+// Plan compares the vaultendpoint inventory file f with the mount in vault.
+// Mount creation, ssh signing key and pki CA generation are only planned
+// when the endpoint is not mounted yet (or -force is set for pki).
+func (c *PutVaultEndpointCommand) Plan(f string) ([]*plan.Change, error) {
+	endpoint := vaultapi.VaultEndpoint{}
+	err := c.Meta.renderInventoryFile("vaultendpoint", f, "VaultEndpoint", &endpoint)
+	if err != nil {
+		return nil, err
+	}
+	ns := endpoint.Spec.VaultNamespace
+	path := endpoint.Spec.Path
+	c.Meta.SecretService.GetClient().SetNamespace(ns)
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+
+	mountData, err := pkiiter.Marshal(endpoint.Spec.MountOptions)
+	if err != nil {
+		return nil, err
+	}
+	mount := make(map[string]interface{})
+	json.Unmarshal(mountData, &mount)
+	tuneData, err := pkiiter.Marshal(endpoint.Spec.TuneOptions)
+	if err != nil {
+		return nil, err
+	}
+	tune := make(map[string]interface{})
+	json.Unmarshal(tuneData, &tune)
+	mount["description"] = c.description(f, &endpoint)
+	tune["description"] = mount["description"]
+
+	changes := []*plan.Change{}
+	current, err := c.Meta.SecretService.Read(fmt.Sprintf("sys/mounts/%s/tune", path))
+	mounted := err == nil && current != nil
+	if !mounted {
+		change := plan.NewChange("vaultendpoint", f, ns, fmt.Sprintf("/sys/mounts/%s", path), mount)
+		change.SetCurrent(nil)
+		changes = append(changes, change)
+	}
+	change := plan.NewChange("vaultendpoint", f, ns, fmt.Sprintf("sys/mounts/%s/tune", path), tune)
+	if mounted {
+		change.SetCurrent(current.Data)
+	} else {
+		change.SetCurrent(nil)
+	}
+	changes = append(changes, change)
+
+	if endpoint.Spec.MountOptions.Type == "ssh" && !mounted {
+		change := plan.NewChange("vaultendpoint", f, ns, fmt.Sprintf("/%s/config/ca", path), map[string]interface{}{"generate_signing_key": true})
+		change.SetCurrent(nil)
+		changes = append(changes, change)
+	}
+	if endpoint.Spec.MountOptions.Type == "pki" && (!mounted || c.FlagForce) {
+		if endpoint.Spec.PKIConfig.RootOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) && !endpoint.Spec.PKIConfig.ExportPrivateKey {
+			data, err := pkiiter.Marshal(endpoint.Spec.PKIConfig.RootOptions.GenerateOptions)
+			if err != nil {
+				return nil, err
+			}
+			m := make(map[string]interface{})
+			json.Unmarshal(data, &m)
+			change := plan.NewChange("vaultendpoint", f, ns, fmt.Sprintf("/%s/root/generate/internal", path), m)
+			change.SetCurrent(nil)
+			changes = append(changes, change)
 		}
-		// End PKI
-		fmt.Printf("Endpoint mount/tune (%s) write OK\n", f)
+		if endpoint.Spec.PKIConfig.IntermediateOptions.GenerateOptions != (*v1.VaultGenerateOptions)(nil) {
+			data, err := pkiiter.Marshal(endpoint.Spec.PKIConfig.IntermediateOptions.GenerateOptions)
+			if err != nil {
+				return nil, err
+			}
+			m := make(map[string]interface{})
+			json.Unmarshal(data, &m)
+			change := plan.NewChange("vaultendpoint", f, ns, fmt.Sprintf("/%s/intermediate/generate/internal", path), m)
+			change.SetCurrent(nil)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
 
+// description is the description of the mount with the ownership marker.
+// The tune description wins over the mount description as tune runs last.
+func (c *PutVaultEndpointCommand) description(f string, endpoint *vaultapi.VaultEndpoint) string {
+	description := endpoint.Spec.TuneOptions.Description
+	if description == "" {
+		description = endpoint.Spec.MountOptions.Description
 	}
+	return c.Meta.ownerMark("vaultendpoint", f, description)
+}
 
-	return 0
+// WriteChange executes a saved plan change. Intermediate CA generation also
+// signs the CSR with the root CA and sets the signed certificate, so it is
+// run from the rendered inventory file rather than as a single write.
+func (c *PutVaultEndpointCommand) WriteChange(change *plan.Change) error {
+	if !strings.HasSuffix(change.Path, "/intermediate/generate/internal") {
+		return c.Meta.writeChange(change)
+	}
+	endpoint := vaultapi.VaultEndpoint{}
+	err := c.Meta.renderInventoryFile("vaultendpoint", change.File, "VaultEndpoint", &endpoint)
+	if err != nil {
+		return err
+	}
+	c.Meta.SecretService.GetClient().SetNamespace(change.Namespace)
+	return c.ConfigureIntermediateCAInternal(change.File, endpoint.Spec.Path, &endpoint)
 }
 
 // ConfigureSSHGenerateSigning configures the endpoint
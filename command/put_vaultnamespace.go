@@ -6,18 +6,25 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutVaultNamespaceCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagDryRun bool
 }
 
 func (c *PutVaultNamespaceCommand) Help() string {
 	helpText := `
-Usage: vault-cli put vaultnamespace [options]
+Usage: vault-cli put vaultnamespace [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -26,8 +33,10 @@ General Options:
 }
 
 func (c *PutVaultNamespaceCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutVaultNamespaceCommand) AutocompleteArgs() complete.Predictor {
@@ -45,6 +54,8 @@ func (c *PutVaultNamespaceCommand) Run(args []string) int {
 	// get the flags specific to this command
 
 	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -60,6 +71,7 @@ func (c *PutVaultNamespaceCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/vaultnamespace/", vaultnamespacefilespec)
 	if err != nil {
@@ -71,41 +83,82 @@ func (c *PutVaultNamespaceCommand) Run(args []string) int {
 		return 1
 	}
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/vaultnamespace/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("vaultnamespace", f, c.Put, c.Plan)
 		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("Namespace", data, c.Meta.flagData)
 		if err != nil {
-			fmt.Printf("unable to apply template to vaultnamespace: %s\n", err.Error())
-			return 1
-		}
-		vaultNamespace := vaultapi.VaultNamespace{}
-		err = yaml.Unmarshal(yamlbytes, &vaultNamespace)
-		if err != nil {
-			fmt.Printf("unable to marshal vaultnamespace: %s\n", err.Error())
+			c.Meta.infof("Vault Namespace: (%s.yaml) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		if vaultNamespace.Spec.NamespaceBase != "" {
-			c.Meta.SecretService.GetClient().SetNamespace(vaultNamespace.Spec.NamespaceBase)
-		}
+	return c.Meta.printPuts()
+}
 
-		secret, err := c.Meta.SecretService.Read(fmt.Sprintf("/sys/namespaces/%s", vaultNamespace.Spec.NamespaceName))
-		if err == nil && secret != nil {
-			fmt.Printf("Vault Namespace: (%s.yaml) %s exists\n", f, vaultNamespace.Spec.NamespaceName)
-			continue
-		}
-		m := make(map[string]interface{})
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("/sys/namespaces/%s", vaultNamespace.Spec.NamespaceName), m)
-		if err != nil {
-			fmt.Printf("Vault Namespace: (%s.yaml) %s %s\n", f, vaultNamespace.Spec.NamespaceName, err)
-			return 1
-		}
-		fmt.Printf("Vault Namespace: (%s.yaml) %s write, OK\n", f, vaultNamespace.Spec.NamespaceName)
+// Render reads the vaultnamespace inventory file f and applies the template data
+func (c *PutVaultNamespaceCommand) Render(f string) (*vaultapi.VaultNamespace, error) {
+	vaultNamespace := vaultapi.VaultNamespace{}
+	err := c.Meta.renderInventoryFile("vaultnamespace", f, "Namespace", &vaultNamespace)
+	if err != nil {
+		return nil, err
 	}
+	return &vaultNamespace, nil
+}
 
-	return 0
+// Put renders the vaultnamespace inventory file f and creates the namespace
+// if it does not already exist
+func (c *PutVaultNamespaceCommand) Put(f string) error {
+	vaultNamespace, err := c.Render(f)
+	if err != nil {
+		return err
+	}
+
+	if vaultNamespace.Spec.NamespaceBase != "" {
+		c.Meta.SecretService.GetClient().SetNamespace(vaultNamespace.Spec.NamespaceBase)
+	}
+	base := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	path := fmt.Sprintf("/sys/namespaces/%s", vaultNamespace.Spec.NamespaceName)
+	err = c.Meta.claim("vaultnamespace", f, base, path)
+	if err != nil {
+		return err
+	}
+
+	secret, err := c.Meta.SecretService.Read(path)
+	if err == nil && secret != nil {
+		return c.Meta.stamp("vaultnamespace", f, base, path)
+	}
+	m := make(map[string]interface{})
+	_, err = c.Meta.SecretService.Write(path, m)
+	if err != nil {
+		return fmt.Errorf("%s %s", vaultNamespace.Spec.NamespaceName, err)
+	}
+	return c.Meta.stamp("vaultnamespace", f, base, path)
+}
+
+// Plan checks whether the namespace in the vaultnamespace inventory file f
+// exists in vault
+func (c *PutVaultNamespaceCommand) Plan(f string) ([]*plan.Change, error) {
+	vaultNamespace, err := c.Render(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if vaultNamespace.Spec.NamespaceBase != "" {
+		c.Meta.SecretService.GetClient().SetNamespace(vaultNamespace.Spec.NamespaceBase)
+	}
+	ns := c.Meta.SecretService.GetClient().Headers().Get("X-Vault-Namespace")
+	path := fmt.Sprintf("/sys/namespaces/%s", vaultNamespace.Spec.NamespaceName)
+	change := plan.NewChange("vaultnamespace", f, ns, path, map[string]interface{}{})
+
+	// the base namespace may not exist yet either, so any error means create
+	secret, err := c.Meta.SecretService.Read(path)
+	if err == nil && secret != nil {
+		change.SetCurrent(map[string]interface{}{})
+	} else {
+		change.SetCurrent(nil)
+	}
+	return []*plan.Change{change}, nil
 }
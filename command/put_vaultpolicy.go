@@ -6,19 +6,26 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	"github.com/posener/complete"
 	"github.com/rodaine/hclencoder"
-	"gopkg.in/yaml.v2"
 )
 
 type PutVaultPolicyCommand struct {
-	Meta Meta
+	Meta       Meta
+	FlagDryRun bool
 }
 
 func (c *PutVaultPolicyCommand) Help() string {
 	helpText := `
-Usage: vault-cli put vaultpolicy [options]
+Usage: vault-cli put vaultpolicy [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -27,8 +34,10 @@ General Options:
 }
 
 func (c *PutVaultPolicyCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutVaultPolicyCommand) AutocompleteArgs() complete.Predictor {
@@ -46,6 +55,8 @@ func (c *PutVaultPolicyCommand) Run(args []string) int {
 	// get the flags specific to this command
 
 	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -61,6 +72,7 @@ func (c *PutVaultPolicyCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/vaultpolicy/", filespec)
 	if err != nil {
@@ -73,43 +85,66 @@ func (c *PutVaultPolicyCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/vaultpolicy/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("vaultpolicy", f, c.Put, c.Plan)
 		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("VaultPolicy", data, c.Meta.flagData)
 		if err != nil {
-			fmt.Printf("unable to apply template to vaultpolicy: %s\n", err.Error())
-			return 1
-		}
-		vaultPolicy := vaultapi.VaultPolicy{}
-		err = yaml.Unmarshal(yamlbytes, &vaultPolicy)
-		if err != nil {
-			fmt.Printf("unable to marshal vaultpolicy: %s\n", err.Error())
+			c.Meta.infof("(%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		c.Meta.SecretService.GetClient().SetNamespace(vaultPolicy.Spec.VaultNamespace)
+	return c.Meta.printPuts()
+}
 
-		hcl, err := hclencoder.Encode(vaultPolicy.Spec.Policies)
-		if err != nil {
-			fmt.Printf("unable to encode: %s", err)
-			return 1
-		}
-		m := make(map[string]interface{})
-		strHCL := string(hcl)
-		m["policy"] = strHCL
+// Put renders the vaultpolicy inventory file f and writes the policy to vault
+func (c *PutVaultPolicyCommand) Put(f string) error {
+	change, err := c.change(f)
+	if err != nil {
+		return err
+	}
+	return c.Meta.writeChange(change)
+}
 
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("sys/policy/%s", vaultPolicy.Spec.PolicyName), m)
-		if err != nil {
-			//cmd.Println(err)
-			fmt.Printf("%v", err)
-			return 1
-		}
-		fmt.Printf("Policy: %s.yaml, Name: %s, write, OK\n", filename, vaultPolicy.Spec.PolicyName)
+// Plan compares the vaultpolicy inventory file f with the policy in vault
+func (c *PutVaultPolicyCommand) Plan(f string) ([]*plan.Change, error) {
+	change, err := c.change(f)
+	if err != nil {
+		return nil, err
+	}
+	c.Meta.SecretService.GetClient().SetNamespace(change.Namespace)
+	secret, err := c.Meta.SecretService.Read(change.Path)
+	if err != nil {
+		return nil, err
+	}
+	// vault returns the policy text as rules
+	var current map[string]interface{}
+	if secret != nil && secret.Data != nil {
+		current = map[string]interface{}{"policy": secret.Data["rules"]}
+	}
+	change.SetCurrent(current)
+	return []*plan.Change{change}, nil
+}
+
+// change renders the vaultpolicy inventory file f into the policy write
+func (c *PutVaultPolicyCommand) change(f string) (*plan.Change, error) {
+	vaultPolicy := vaultapi.VaultPolicy{}
+	err := c.Meta.renderInventoryFile("vaultpolicy", f, "VaultPolicy", &vaultPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	hcl, err := hclencoder.Encode(vaultPolicy.Spec.Policies)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode: %s", err)
 	}
+	m := make(map[string]interface{})
+	strHCL := string(hcl)
+	m["policy"] = strHCL
 
-	return 0
+	path := fmt.Sprintf("sys/policy/%s", vaultPolicy.Spec.PolicyName)
+	return plan.NewChange("vaultpolicy", f, vaultPolicy.Spec.VaultNamespace, path, m), nil
 }
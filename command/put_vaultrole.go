@@ -7,10 +7,10 @@ import (
 	"strings"
 
 	"github.com/ibm/vault-cli/pkg/inventory"
+	"github.com/ibm/vault-cli/pkg/plan"
 	vaultapi "github.com/ibm/vault-go/api/v1"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/posener/complete"
-	"gopkg.in/yaml.v2"
 )
 
 type PutVaultRoleCommand struct {
@@ -18,11 +18,18 @@ type PutVaultRoleCommand struct {
 	FlagPolicies                 string
 	FlagBoundNamespaces          string
 	FlagBoundServiceAccountNames string
+	FlagDryRun                   bool
 }
 
 func (c *PutVaultRoleCommand) Help() string {
 	helpText := `
-Usage: vault-cli put vaultrole [options]
+Usage: vault-cli put vaultrole [options] <filespec>
+
+Put Options:
+  -dry-run
+    Print the field level changes the put would make without writing them.
+
+  ` + eventOptionsUsage() + `
 
 General Options:
   ` + generalOptionsUsage() + `
@@ -31,8 +38,10 @@ General Options:
 }
 
 func (c *PutVaultRoleCommand) AutocompleteFlags() complete.Flags {
-	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(),
-		complete.Flags{})
+	return mergeAutocompleteFlags(c.Meta.AutocompleteFlags(), eventAutocompleteFlags(),
+		complete.Flags{
+			"-dry-run": complete.PredictNothing,
+		})
 }
 
 func (c *PutVaultRoleCommand) AutocompleteArgs() complete.Predictor {
@@ -51,6 +60,8 @@ func (c *PutVaultRoleCommand) Run(args []string) int {
 
 	flagSet := c.Meta.FlagSet(c.Name())
 	flagSet.StringVar(&c.FlagPolicies, "policies", "", "")
+	flagSet.BoolVar(&c.FlagDryRun, "dry-run", false, "")
+	c.Meta.eventFlags(flagSet)
 	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
 	if err := flagSet.Parse(args); err != nil {
 		return 1
@@ -66,6 +77,7 @@ func (c *PutVaultRoleCommand) Run(args []string) int {
 		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
 		return 1
 	}
+	defer c.Meta.closeEvents()
 
 	files, err := inventory.GetFiles(c.Meta.CurrentContext.InventoryPath+"/vaultrole/", filespec)
 	if err != nil {
@@ -78,62 +90,84 @@ func (c *PutVaultRoleCommand) Run(args []string) int {
 	}
 
 	for _, f := range files {
-		filename := c.Meta.CurrentContext.InventoryPath + "/vaultrole/" + f
-		data, err := inventory.ReadFile(filename + ".yaml")
-		if err != nil {
-			fmt.Println("error reading file: ", err.Error())
-			return 1
+		if c.FlagDryRun {
+			err = c.Meta.dryRun(f, c.Plan)
+		} else {
+			_, err = c.Meta.putFile("vaultrole", f, c.Put, c.Plan)
 		}
-		yamlbytes, err := c.Meta.TemplateService.Exec("VaultRole", data, c.Meta.flagData)
 		if err != nil {
-			fmt.Printf("unable to apply template to vaultrole: %s\n", err.Error())
-			return 1
-		}
-		vaultRole := vaultapi.VaultRole{}
-		err = yaml.Unmarshal(yamlbytes, &vaultRole)
-		if err != nil {
-			fmt.Printf("unable to marshal vaultrole: %s\n", err.Error())
+			c.Meta.infof("Role (%s) %s\n", f, err)
+			c.Meta.printPuts()
 			return 1
 		}
+	}
 
-		authMethod := vaultRole.Spec.AuthMethod
-		roleName := vaultRole.Spec.RoleName
-		c.Meta.SecretService.GetClient().SetNamespace(vaultRole.Spec.VaultNamespace)
+	return c.Meta.printPuts()
+}
 
-		if c.FlagPolicies != "" {
-			pols := strings.Split(c.FlagPolicies, ",")
-			for _, v := range pols {
-				vaultRole.Spec.Data.Policies = append(vaultRole.Spec.Data.Policies, v)
-				vaultRole.Spec.Data.TokenPolicies = append(vaultRole.Spec.Data.TokenPolicies, v)
-			}
-		}
-		if c.FlagBoundNamespaces != "" {
-			pols := strings.Split(c.FlagBoundNamespaces, ",")
-			for _, v := range pols {
-				vaultRole.Spec.Data.BoundServiceAccountNamespaces = append(vaultRole.Spec.Data.BoundServiceAccountNamespaces, v)
-			}
-		}
-		if c.FlagBoundServiceAccountNames != "" {
-			bsans := strings.Split(c.FlagBoundServiceAccountNames, ",")
-			for _, v := range bsans {
-				vaultRole.Spec.Data.BoundServiceAccountNames = append(vaultRole.Spec.Data.BoundServiceAccountNames, v)
-			}
-		}
+// Put renders the vaultrole inventory file f and writes the role to vault
+func (c *PutVaultRoleCommand) Put(f string) error {
+	change, err := c.change(f)
+	if err != nil {
+		return err
+	}
+	return c.Meta.writeChange(change)
+}
+
+// Plan compares the vaultrole inventory file f with the role in vault
+func (c *PutVaultRoleCommand) Plan(f string) ([]*plan.Change, error) {
+	change, err := c.change(f)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Meta.planChange(change)
+	if err != nil {
+		return nil, err
+	}
+	return []*plan.Change{change}, nil
+}
 
-		// unmarshal the data
-		pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
-		data, err = pkiiter.Marshal(vaultRole.Spec.Data)
-		m := make(map[string]interface{})
-		json.Unmarshal(data, &m)
+// change renders the vaultrole inventory file f, adds any policies or
+// bindings given on the command line and returns the role write
+func (c *PutVaultRoleCommand) change(f string) (*plan.Change, error) {
+	vaultRole := vaultapi.VaultRole{}
+	err := c.Meta.renderInventoryFile("vaultrole", f, "VaultRole", &vaultRole)
+	if err != nil {
+		return nil, err
+	}
 
-		_, err = c.Meta.SecretService.Write(fmt.Sprintf("auth/%s/role/%s", authMethod, roleName), m)
-		if err != nil {
-			fmt.Printf("Role (%s) %s", filename, err)
-			return 1
+	authMethod := vaultRole.Spec.AuthMethod
+	roleName := vaultRole.Spec.RoleName
+
+	if c.FlagPolicies != "" {
+		pols := strings.Split(c.FlagPolicies, ",")
+		for _, v := range pols {
+			vaultRole.Spec.Data.Policies = append(vaultRole.Spec.Data.Policies, v)
+			vaultRole.Spec.Data.TokenPolicies = append(vaultRole.Spec.Data.TokenPolicies, v)
+		}
+	}
+	if c.FlagBoundNamespaces != "" {
+		pols := strings.Split(c.FlagBoundNamespaces, ",")
+		for _, v := range pols {
+			vaultRole.Spec.Data.BoundServiceAccountNamespaces = append(vaultRole.Spec.Data.BoundServiceAccountNamespaces, v)
 		}
-		fmt.Printf(string("Role: %s.yaml, Method: %s, Name: %s  write OK\n"), filename, authMethod, roleName)
+	}
+	if c.FlagBoundServiceAccountNames != "" {
+		bsans := strings.Split(c.FlagBoundServiceAccountNames, ",")
+		for _, v := range bsans {
+			vaultRole.Spec.Data.BoundServiceAccountNames = append(vaultRole.Spec.Data.BoundServiceAccountNames, v)
+		}
+	}
 
+	// unmarshal the data
+	pkiiter := jsoniter.Config{TagKey: "vault"}.Froze()
+	data, err := pkiiter.Marshal(vaultRole.Spec.Data)
+	if err != nil {
+		return nil, err
 	}
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
 
-	return 0
+	path := fmt.Sprintf("auth/%s/role/%s", authMethod, roleName)
+	return plan.NewChange("vaultrole", f, vaultRole.Spec.VaultNamespace, path, m), nil
 }
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/posener/complete"
+)
+
+type StatusCommand struct {
+	Meta Meta
+}
+
+// statusResult is what status prints
+type statusResult struct {
+	Context     string `json:"context"`
+	Cluster     string `json:"cluster"`
+	Server      string `json:"server"`
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	Standby     bool   `json:"standby"`
+	SealType    string `json:"sealType,omitempty"`
+	Threshold   int    `json:"threshold,omitempty"`
+	Shares      int    `json:"shares,omitempty"`
+	Progress    int    `json:"progress,omitempty"`
+	Version     string `json:"version"`
+	ClusterName string `json:"clusterName,omitempty"`
+	HAEnabled   bool   `json:"haEnabled"`
+	Leader      string `json:"leader,omitempty"`
+}
+
+func (c *StatusCommand) Help() string {
+	helpText := `
+Usage: vault-cli status [options]
+
+  Prints whether the vault of the context's cluster is initialized and
+  sealed, its version, and its leader when it runs in high availability
+  mode. status does not log in, it only reads unauthenticated endpoints.
+
+  Exits with 2 when vault is sealed or not initialized.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StatusCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *StatusCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *StatusCommand) Synopsis() string {
+	return "status prints the seal status and leader of the context's vault"
+}
+
+func (c *StatusCommand) Name() string { return "status" }
+
+func (c *StatusCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	ctx, _, err := c.Meta.loadContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	cluster := c.Meta.Config.GetClusterByName(ctx.Cluster)
+	client, err := config.NewClient(cluster, c.Meta.Config.GetUserByName(ctx.User), "", "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	health, err := client.Sys().Health()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to read the health of %s: %s\n", cluster.Server, err)
+		return 1
+	}
+	result := &statusResult{
+		Context:     ctx.Name,
+		Cluster:     ctx.Cluster,
+		Server:      cluster.Server,
+		Initialized: health.Initialized,
+		Sealed:      health.Sealed,
+		Standby:     health.Standby,
+		Version:     health.Version,
+		ClusterName: health.ClusterName,
+	}
+	seal, err := client.Sys().SealStatus()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to read the seal status of %s: %s\n", cluster.Server, err)
+		return 1
+	}
+	result.SealType, result.Threshold, result.Shares, result.Progress = seal.Type, seal.T, seal.N, seal.Progress
+	// a sealed vault has no leader to ask for
+	if !health.Sealed && health.Initialized {
+		leader, err := client.Sys().Leader()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to read the leader of %s: %s\n", cluster.Server, err)
+			return 1
+		}
+		result.HAEnabled, result.Leader = leader.HAEnabled, leader.LeaderAddress
+	}
+
+	err = c.Meta.output(result, func(w io.Writer) error {
+		fmt.Fprintf(w, "Context:      %s\n", result.Context)
+		fmt.Fprintf(w, "Server:       %s\n", result.Server)
+		fmt.Fprintf(w, "Initialized:  %t\n", result.Initialized)
+		fmt.Fprintf(w, "Sealed:       %t\n", result.Sealed)
+		if result.SealType != "" {
+			fmt.Fprintf(w, "Seal type:    %s\n", result.SealType)
+		}
+		if result.Sealed {
+			fmt.Fprintf(w, "Unseal:       %d of %d keys\n", result.Progress, result.Threshold)
+		}
+		fmt.Fprintf(w, "Version:      %s\n", result.Version)
+		if result.ClusterName != "" {
+			fmt.Fprintf(w, "Cluster name: %s\n", result.ClusterName)
+		}
+		fmt.Fprintf(w, "HA enabled:   %t\n", result.HAEnabled)
+		if result.HAEnabled {
+			fmt.Fprintf(w, "Standby:      %t\n", result.Standby)
+			fmt.Fprintf(w, "Leader:       %s\n", result.Leader)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if result.Sealed || !result.Initialized {
+		return 2
+	}
+	return 0
+}
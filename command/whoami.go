@@ -0,0 +1,123 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/posener/complete"
+)
+
+type WhoamiCommand struct {
+	Meta Meta
+}
+
+// whoamiResult is what whoami prints, the token properties of lookup-self
+type whoamiResult struct {
+	Context          string   `json:"context"`
+	DisplayName      string   `json:"displayName"`
+	Policies         []string `json:"policies"`
+	IdentityPolicies []string `json:"identityPolicies,omitempty"`
+	EntityID         string   `json:"entityID,omitempty"`
+	TTL              int64    `json:"ttl"`
+	Renewable        bool     `json:"renewable"`
+	Namespace        string   `json:"namespace"`
+}
+
+func (c *WhoamiCommand) Help() string {
+	helpText := `
+Usage: vault-cli whoami [options]
+
+  Prints the display name, policies, entity id, remaining ttl and namespace
+  of the token of the context, logging in when its session expired.
+
+General Options:
+  ` + generalOptionsUsage() + `
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *WhoamiCommand) AutocompleteFlags() complete.Flags {
+	return c.Meta.AutocompleteFlags()
+}
+
+func (c *WhoamiCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *WhoamiCommand) Synopsis() string {
+	return "whoami prints the token of the context"
+}
+
+func (c *WhoamiCommand) Name() string { return "whoami" }
+
+func (c *WhoamiCommand) Run(args []string) int {
+
+	// get the flags specific to this command
+
+	flagSet := c.Meta.FlagSet(c.Name())
+	flagSet.Usage = func() { c.Meta.Ui.Output(c.Help()) }
+	if err := flagSet.Parse(args); err != nil {
+		return 1
+	}
+	if len(flagSet.Args()) > 0 {
+		c.Meta.Ui.Error("This command takes no arguments")
+		return 1
+	}
+
+	// load config
+	err := c.Meta.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Meta Load error: %s\n", err.Error())
+		return 1
+	}
+
+	self, err := c.Meta.SecretService.GetClient().Auth().Token().LookupSelf()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to look up the token: %s\n", err)
+		return 1
+	}
+	result := &whoamiResult{Context: c.Meta.CurrentContext.Name, Policies: []string{}}
+	result.DisplayName, _ = self.Data["display_name"].(string)
+	result.EntityID, _ = self.Data["entity_id"].(string)
+	// TokenPolicies splits the token and identity policies into Auth
+	if _, err := self.TokenPolicies(); err == nil && self.Auth != nil {
+		result.Policies = append(result.Policies, self.Auth.TokenPolicies...)
+		result.IdentityPolicies = self.Auth.IdentityPolicies
+	}
+	result.Renewable, _ = self.TokenIsRenewable()
+	ttl, _ := self.TokenTTL()
+	result.TTL = int64(ttl.Seconds())
+	// the namespace of the token, the root namespace has no path
+	result.Namespace, _ = self.Data["namespace_path"].(string)
+	result.Namespace = strings.TrimSuffix(result.Namespace, "/")
+	if result.Namespace == "" {
+		result.Namespace = "root"
+	}
+
+	err = c.Meta.output(result, func(w io.Writer) error {
+		fmt.Fprintf(w, "Context:      %s\n", result.Context)
+		fmt.Fprintf(w, "Display name: %s\n", result.DisplayName)
+		fmt.Fprintf(w, "Policies:     %s\n", strings.Join(result.Policies, ", "))
+		if len(result.IdentityPolicies) > 0 {
+			fmt.Fprintf(w, "Identity:     %s\n", strings.Join(result.IdentityPolicies, ", "))
+		}
+		if result.EntityID != "" {
+			fmt.Fprintf(w, "Entity ID:    %s\n", result.EntityID)
+		}
+		ttl := "never expires"
+		if result.TTL > 0 {
+			ttl = (time.Duration(result.TTL) * time.Second).String()
+		}
+		fmt.Fprintf(w, "TTL:          %s (renewable: %t)\n", ttl, result.Renewable)
+		fmt.Fprintf(w, "Namespace:    %s\n", result.Namespace)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	return 0
+}
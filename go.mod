@@ -3,6 +3,7 @@ module github.com/ibm/vault-cli
 go 1.15
 
 require (
+	github.com/hashicorp/hcl v1.0.1-vault
 	github.com/hashicorp/vault v1.7.0
 	github.com/hashicorp/vault/api v1.0.5-0.20210210214158-405eced08457
 	github.com/ibm/vault-go v0.0.0-20210401194419-ffb095ea9913
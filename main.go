@@ -37,6 +37,9 @@ var (
 
 	// Common commands are grouped separately to call them out to operators.
 	commonCommands = []string{
+		"apply",
+		"plan",
+		"prune",
 		"put",
 	}
 )
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Authenticator logs in to vault with an auth method
+//go:generate counterfeiter -o fakes/authenticator.go --fake-name FakeAuthenticator . Authenticator
+type Authenticator interface {
+	// Login logs in at the auth method mounted at path, like "approle",
+	// with client, which holds the address, TLS config and namespace to
+	// log in with, and returns the secret holding the token
+	Login(client *api.Client, path string) (*api.Secret, error)
+}
+
+// Credentials are what a user logs in with, each auth method uses the
+// fields it needs
+type Credentials struct {
+	Username string
+	Password string
+	RoleID   string
+	SecretID string
+	Role     string
+	JWT      string
+	Token    string
+}
+
+// Factory returns the Authenticator of an auth method for credentials
+type Factory func(credentials Credentials) (Authenticator, error)
+
+// Registry maps the names of auth methods to their authenticators, the
+// zero value has no methods
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry returns a registry of the auth methods of vault-cli: cert,
+// userpass, ldap, okta, radius, approle, jwt, kubernetes and token
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.Register("cert", NewCert)
+	for _, method := range PasswordMethods {
+		r.Register(method, NewPassword)
+	}
+	r.Register("approle", NewAppRole)
+	r.Register("jwt", NewRole)
+	r.Register("kubernetes", NewRole)
+	r.Register("token", NewToken)
+	return r
+}
+
+// Default is the registry logins use unless the config has its own
+var Default = NewRegistry()
+
+// Register adds the auth method of factory to the default registry
+func Register(method string, factory Factory) {
+	Default.Register(method, factory)
+}
+
+// Register adds the auth method of factory, replacing one of the same name
+func (r *Registry) Register(method string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.factories == nil {
+		r.factories = map[string]Factory{}
+	}
+	r.factories[method] = factory
+}
+
+// New returns the authenticator of method for credentials
+func (r *Registry) New(method string, credentials Credentials) (Authenticator, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[method]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown auth method %s, known methods: %s", method, strings.Join(r.Methods(), ", "))
+	}
+	return factory(credentials)
+}
+
+// Methods returns the names of the registered auth methods, sorted
+func (r *Registry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	methods := []string{}
+	for method := range r.factories {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,163 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/auth"
+	"github.com/ibm/vault-cli/pkg/auth/fakes"
+)
+
+// loginVault answers logins at the paths of want with a token when the
+// body matches, and token lookups of s.given
+func loginVault(t *testing.T, want map[string]map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ns := r.Header.Get("X-Vault-Namespace"); ns != "team" {
+			t.Errorf("unexpected namespace %q", ns)
+		}
+		if r.URL.Path == "/v1/auth/token/lookup-self" && r.Header.Get("X-Vault-Token") == "s.given" {
+			w.Write([]byte(`{"data":{"ttl":600,"renewable":true}}`))
+			return
+		}
+		// a login without data sends no body or null
+		var body map[string]string
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %s", err)
+			}
+		}
+		if body == nil {
+			body = map[string]string{}
+		}
+		expected, ok := want[r.URL.Path]
+		if !ok || !reflect.DeepEqual(body, expected) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["bad login"]}`))
+			return
+		}
+		w.Write([]byte(`{"auth":{"client_token":"s.login","lease_duration":3600,"renewable":true}}`))
+	}))
+}
+
+func TestAuthenticators(t *testing.T) {
+	t.Parallel()
+
+	server := loginVault(t, map[string]map[string]string{
+		"/v1/auth/cert/login":          {},
+		"/v1/auth/corp-ldap/login/me":  {"password": "pw"},
+		"/v1/auth/approle/login":       {"role_id": "r", "secret_id": "s"},
+		"/v1/auth/kubernetes/login":    {"role": "app", "jwt": "sa.jwt"},
+		"/v1/auth/gitlab/login":        {"role": "ci", "jwt": "ci.jwt"},
+		"/v1/auth/userpass-ci/login/x": {"password": "y"},
+	})
+	defer server.Close()
+
+	registry := auth.NewRegistry()
+	for _, tc := range []struct {
+		method      string
+		path        string
+		credentials auth.Credentials
+	}{
+		{"cert", "cert", auth.Credentials{}},
+		{"ldap", "corp-ldap", auth.Credentials{Username: "me", Password: "pw"}},
+		{"userpass", "/userpass-ci/", auth.Credentials{Username: "x", Password: "y"}},
+		{"approle", "approle", auth.Credentials{RoleID: "r", SecretID: "s"}},
+		{"kubernetes", "kubernetes", auth.Credentials{Role: "app", JWT: "sa.jwt"}},
+		{"jwt", "gitlab", auth.Credentials{Role: "ci", JWT: "ci.jwt"}},
+	} {
+		client := vaultClient(t, server.URL)
+		authenticator, err := registry.New(tc.method, tc.credentials)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, err := authenticator.Login(client, tc.path)
+		if err != nil {
+			t.Errorf("%s: %s", tc.method, err)
+			continue
+		}
+		if secret.Auth.ClientToken != "s.login" || secret.Auth.LeaseDuration != 3600 {
+			t.Errorf("%s: unexpected auth %+v", tc.method, secret.Auth)
+		}
+	}
+
+	// a rejected login is an error
+	authenticator, err := registry.New("approle", auth.Credentials{RoleID: "r", SecretID: "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.Login(vaultClient(t, server.URL), "approle"); err == nil {
+		t.Errorf("expected an error for a rejected login")
+	}
+
+	// a token is looked up for its ttl
+	authenticator, err = registry.New("token", auth.Credentials{Token: "s.given"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := authenticator.Login(vaultClient(t, server.URL), "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Auth.ClientToken != "s.given" || secret.Auth.LeaseDuration != 600 || !secret.Auth.Renewable {
+		t.Errorf("unexpected token auth %+v", secret.Auth)
+	}
+
+	for method, credentials := range map[string]auth.Credentials{
+		"approle":  {SecretID: "s"},
+		"userpass": {Password: "pw"},
+		"jwt":      {Role: "ci"},
+		"token":    {},
+		"unknown":  {},
+	} {
+		if _, err := registry.New(method, credentials); err == nil {
+			t.Errorf("%s: expected an error for %+v", method, credentials)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := &auth.Registry{}
+	if methods := registry.Methods(); len(methods) != 0 {
+		t.Errorf("expected no methods, got %v", methods)
+	}
+	fake := &fakes.FakeAuthenticator{}
+	fake.LoginReturns(&api.Secret{Auth: &api.SecretAuth{ClientToken: "s.plugin"}}, nil)
+	registry.Register("plugin", func(credentials auth.Credentials) (auth.Authenticator, error) {
+		return fake, nil
+	})
+	authenticator, err := registry.New("plugin", auth.Credentials{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := authenticator.Login(nil, "plugin")
+	if err != nil || secret.Auth.ClientToken != "s.plugin" {
+		t.Errorf("unexpected login %+v %v", secret, err)
+	}
+	if _, path := fake.LoginArgsForCall(0); path != "plugin" {
+		t.Errorf("unexpected path %s", path)
+	}
+	expected := []string{"approle", "cert", "jwt", "kubernetes", "ldap", "okta", "radius", "token", "userpass"}
+	if methods := auth.NewRegistry().Methods(); !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+}
+
+// vaultClient returns a client of address in namespace team
+func vaultClient(t *testing.T, address string) *api.Client {
+	cfg := api.DefaultConfig()
+	cfg.Address = address
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ClearToken()
+	client.SetNamespace("team")
+	client.SetMaxRetries(0)
+	return client
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// PasswordMethods log in with a username and password at
+// auth/<path>/login/<username>
+var PasswordMethods = []string{"userpass", "ldap", "okta", "radius"}
+
+// Cert logs in with the client certificate of the TLS config of the client
+type Cert struct{}
+
+// NewCert returns a Cert, its credentials are in the TLS config
+func NewCert(credentials Credentials) (Authenticator, error) {
+	return &Cert{}, nil
+}
+
+func (a *Cert) Login(client *api.Client, path string) (*api.Secret, error) {
+	return login(client, loginPath(path), nil)
+}
+
+// Password logs in with a username and password, for the userpass, ldap,
+// okta and radius auth methods
+type Password struct {
+	Username string
+	Password string
+}
+
+// NewPassword returns a Password for the username and password of
+// credentials
+func NewPassword(credentials Credentials) (Authenticator, error) {
+	if credentials.Username == "" {
+		return nil, errors.New("password login requires a username")
+	}
+	return &Password{Username: credentials.Username, Password: credentials.Password}, nil
+}
+
+func (a *Password) Login(client *api.Client, path string) (*api.Secret, error) {
+	return login(client, loginPath(path)+"/"+a.Username, map[string]interface{}{"password": a.Password})
+}
+
+// AppRole logs in with a role id and secret id
+type AppRole struct {
+	RoleID   string
+	SecretID string
+}
+
+// NewAppRole returns an AppRole for the role id and secret id of
+// credentials
+func NewAppRole(credentials Credentials) (Authenticator, error) {
+	if credentials.RoleID == "" {
+		return nil, errors.New("approle login requires a role id")
+	}
+	return &AppRole{RoleID: credentials.RoleID, SecretID: credentials.SecretID}, nil
+}
+
+func (a *AppRole) Login(client *api.Client, path string) (*api.Secret, error) {
+	return login(client, loginPath(path), map[string]interface{}{"role_id": a.RoleID, "secret_id": a.SecretID})
+}
+
+// Role logs in as a role with a jwt, for the jwt and kubernetes auth
+// methods
+type Role struct {
+	Role string
+	JWT  string
+}
+
+// NewRole returns a Role for the role and jwt of credentials
+func NewRole(credentials Credentials) (Authenticator, error) {
+	if credentials.JWT == "" {
+		return nil, errors.New("role login requires a jwt")
+	}
+	return &Role{Role: credentials.Role, JWT: credentials.JWT}, nil
+}
+
+func (a *Role) Login(client *api.Client, path string) (*api.Secret, error) {
+	return login(client, loginPath(path), map[string]interface{}{"role": a.Role, "jwt": a.JWT})
+}
+
+// Token uses an existing token. Its login looks the token up, so that the
+// secret has the ttl the token has left.
+type Token struct {
+	Token string
+}
+
+// NewToken returns a Token for the token of credentials
+func NewToken(credentials Credentials) (Authenticator, error) {
+	if credentials.Token == "" {
+		return nil, errors.New("token login requires a token")
+	}
+	return &Token{Token: credentials.Token}, nil
+}
+
+// Login looks the token up with client, which gets the token, path is the
+// token auth method
+func (a *Token) Login(client *api.Client, path string) (*api.Secret, error) {
+	client.SetToken(a.Token)
+	self, err := client.Auth().Token().LookupSelf()
+	if err != nil {
+		return nil, fmt.Errorf("could not look up token: %s", err)
+	}
+	ttl, err := self.TokenTTL()
+	if err != nil {
+		return nil, err
+	}
+	renewable, err := self.TokenIsRenewable()
+	if err != nil {
+		return nil, err
+	}
+	self.Auth = &api.SecretAuth{ClientToken: a.Token, LeaseDuration: int(ttl.Seconds()), Renewable: renewable}
+	return self, nil
+}
+
+// OIDCAuthURL returns the url of the identity provider to log in as role
+// at the oidc auth method mounted at path, which redirects the browser to
+// redirectURI
+func OIDCAuthURL(client *api.Client, path, role, redirectURI, nonce string) (string, error) {
+	secret, err := client.Logical().Write("auth/"+strings.Trim(path, "/")+"/oidc/auth_url", map[string]interface{}{
+		"role":         role,
+		"redirect_uri": redirectURI,
+		"client_nonce": nonce,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not get auth url: %s", err)
+	}
+	// vault answers an empty url with a warning when the redirect uri is
+	// not allowed for the role
+	var authURL string
+	if secret != nil {
+		authURL, _ = secret.Data["auth_url"].(string)
+	}
+	if authURL == "" {
+		return "", fmt.Errorf("could not get auth url for %s, is it an allowed redirect uri of role %s?", redirectURI, role)
+	}
+	return authURL, nil
+}
+
+// OIDCCallback returns the secret holding the token for the state and code
+// the identity provider redirected the browser with
+func OIDCCallback(client *api.Client, path, state, code, nonce string) (*api.Secret, error) {
+	secret, err := client.Logical().ReadWithData("auth/"+strings.Trim(path, "/")+"/oidc/callback", map[string][]string{
+		"state":        {state},
+		"code":         {code},
+		"client_nonce": {nonce},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get token: %s", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, errors.New("could not get token: the oidc callback returned none")
+	}
+	return secret, nil
+}
+
+// loginPath returns the login endpoint of the auth method mounted at path
+func loginPath(path string) string {
+	return "auth/" + strings.Trim(path, "/") + "/login"
+}
+
+// login writes data to the login endpoint at path and returns the secret
+// holding the token
+func login(client *api.Client, path string, data map[string]interface{}) (*api.Secret, error) {
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not log in at %s: %s", path, err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("could not log in at %s: no token in the response", path)
+	}
+	return secret, nil
+}
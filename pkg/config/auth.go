@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/auth"
+)
+
+// PasswordAuthMethods log in with a username and password at
+// auth/<path>/login/<username>
+var PasswordAuthMethods = auth.PasswordMethods
+
+// IsPasswordAuthMethod returns true if method logs in with a username and
+// password
+func IsPasswordAuthMethod(method string) bool {
+	for _, m := range PasswordAuthMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// loginPath returns the auth method the user logs in with, one of methods
+// the first being the default, and the mount it logs in at
+func (u *User) loginPath(methods ...string) (string, string, error) {
+	method := methods[0]
+	if u.AuthMethod != "" {
+		method = ""
+		for _, m := range methods {
+			if m == u.AuthMethod {
+				method = m
+			}
+		}
+		if method == "" {
+			return "", "", fmt.Errorf("user %s has auth-method %s, expected %s for its credentials", u.Name, u.AuthMethod, strings.Join(methods, ", "))
+		}
+	}
+	if u.AuthPath != "" {
+		return method, strings.Trim(u.AuthPath, "/"), nil
+	}
+	return method, method, nil
+}
+
+// authenticate logs in with client at the auth method mounted at path,
+// with the authenticator of method for credentials
+func (cfg *Config) authenticate(client *api.Client, method, path string, credentials auth.Credentials) (*api.Secret, error) {
+	registry := cfg.Authenticators
+	if registry == nil {
+		registry = auth.Default
+	}
+	authenticator, err := registry.New(method, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return authenticator.Login(client, path)
+}
+
+// login logs in user with its credentials. The client holds the address,
+// TLS config and namespace of the login.
+func (cfg *Config) login(client *api.Client, user *User) (*api.Secret, error) {
+	switch {
+	case user.Kubernetes != nil:
+		jwt, err := kubernetesJWT(user)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, "kubernetes", mountOr(user.Kubernetes.Mount, "kubernetes"), auth.Credentials{Role: user.Kubernetes.Role, JWT: jwt})
+	case user.JWT != nil:
+		jwt, err := userJWT(user)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, "jwt", mountOr(user.JWT.Mount, "jwt"), auth.Credentials{Role: user.JWT.Role, JWT: jwt})
+	case user.ClientCert != "" || user.ClientCertData != "":
+		method, path, err := user.loginPath("cert")
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, method, path, auth.Credentials{})
+	case user.Username != "":
+		method, path, err := user.loginPath(PasswordAuthMethods...)
+		if err != nil {
+			return nil, err
+		}
+		password, err := cfg.secret(user.Password, user.PasswordRef)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, method, path, auth.Credentials{Username: user.Username, Password: password})
+	case user.RoleID != "":
+		method, path, err := user.loginPath("approle")
+		if err != nil {
+			return nil, err
+		}
+		secretID, err := cfg.secret(user.SecretID, user.SecretIDRef)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, method, path, auth.Credentials{RoleID: user.RoleID, SecretID: secretID})
+	}
+	return nil, fmt.Errorf("GetSession login requires credentials")
+}
+
+// mountOr returns mount, or def when it is not set
+func mountOr(mount, def string) string {
+	if mount == "" {
+		return def
+	}
+	return mount
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/auth"
+	authfakes "github.com/ibm/vault-cli/pkg/auth/fakes"
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+// logins records the logins of a config through a fake authenticator
+type logins struct {
+	*authfakes.FakeAuthenticator
+	mu          sync.Mutex
+	methods     []string
+	credentials []auth.Credentials
+}
+
+// fakeLogins makes cfg log in with a fake authenticator returning secret
+// for methods
+func fakeLogins(cfg *config.Config, secret *api.Secret, methods ...string) *logins {
+	l := &logins{FakeAuthenticator: &authfakes.FakeAuthenticator{}}
+	l.LoginReturns(secret, nil)
+	cfg.Authenticators = auth.NewRegistry()
+	for _, method := range methods {
+		method := method
+		cfg.Authenticators.Register(method, func(credentials auth.Credentials) (auth.Authenticator, error) {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.methods = append(l.methods, method)
+			l.credentials = append(l.credentials, credentials)
+			return l.FakeAuthenticator, nil
+		})
+	}
+	return l
+}
+
+func TestAuthPath(t *testing.T) {
+	t.Parallel()
+
+	login := &api.Secret{Auth: &api.SecretAuth{ClientToken: "s.login", LeaseDuration: 3600}}
+	for _, tc := range []struct {
+		spec   config.UserSpec
+		method string
+		path   string
+	}{
+		{config.UserSpec{Username: "me", Password: "pw"}, "userpass", "userpass"},
+		{config.UserSpec{Username: "me", Password: "pw", AuthMethod: "ldap"}, "ldap", "ldap"},
+		{config.UserSpec{Username: "me", Password: "pw", AuthMethod: "okta", AuthPath: "corp-okta"}, "okta", "corp-okta"},
+		{config.UserSpec{Username: "me", Password: "pw", AuthPath: "/userpass-ci/"}, "userpass", "userpass-ci"},
+	} {
+		cfg := tokenConfig(tc.spec)
+		l := fakeLogins(cfg, login, config.PasswordAuthMethods...)
+		if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err != nil {
+			t.Fatal(err)
+		}
+		client, path := l.LoginArgsForCall(0)
+		credentials := l.credentials[0]
+		if l.methods[0] != tc.method || path != tc.path || credentials.Username != "me" || credentials.Password != "pw" {
+			t.Errorf("expected %s login at %s, got %s at %s %+v", tc.method, tc.path, l.methods[0], path, credentials)
+		}
+		if ns := client.Headers().Get("X-Vault-Namespace"); ns != "team" {
+			t.Errorf("unexpected namespace %q", ns)
+		}
+	}
+
+	cfg := tokenConfig(config.UserSpec{RoleID: "role", SecretID: "secret", AuthPath: "approle-ci"})
+	l := fakeLogins(cfg, login, "approle")
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, path := l.LoginArgsForCall(0); path != "approle-ci" || l.credentials[0].SecretID != "secret" {
+		t.Errorf("expected login at approle-ci, got %s %+v", path, l.credentials[0])
+	}
+
+	cfg = tokenConfig(config.UserSpec{RoleID: "role", SecretID: "secret", AuthMethod: "ldap"})
+	fakeLogins(cfg, login, "approle", "ldap")
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err == nil {
+		t.Errorf("expected an error for an approle user with auth-method ldap")
+	}
+
+	// an auth method vault-cli does not know needs its authenticator
+	cfg = tokenConfig(config.UserSpec{Username: "me", AuthMethod: "ldap"})
+	cfg.Authenticators = &auth.Registry{}
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err == nil {
+		t.Errorf("expected an error for an unregistered auth method")
+	}
+}
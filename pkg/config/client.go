@@ -1,8 +1,16 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/ibm/vault-cli/pkg/inventory"
@@ -10,27 +18,151 @@ import (
 	"github.com/ibm/vault-cli/pkg/secretservice/vault"
 )
 
-// GetDefaultClient attempt to login ot vault
+// GetDefaultClient returns a client of the vault at url with token, cert and
+// key authenticate the client and cacert verifies the server
 func (cfg *Config) GetDefaultClient(namespace, url, token, cert, key, cacert string) (*api.Client, error) {
-	//token = VaultLogin(namespace, url, cert, key, cacert)
-	os.Setenv("VAULT_ADDR", url)
-	os.Setenv("VAULT_CLIENT_CERT", inventory.ExpandHomePath(cert))
-	os.Setenv("VAULT_CLIENT_KEY", inventory.ExpandHomePath(key))
-	os.Setenv("VAULT_CACERT", inventory.ExpandHomePath(cacert))
-	os.Setenv("VAULT_TOKEN", token)
-	apiCfg := api.DefaultConfig()
-	apiClient, err := api.NewClient(apiCfg)
+	cluster := &Cluster{ClusterSpec: ClusterSpec{Server: url, CertAuth: cacert}}
+	user := &User{UserSpec: UserSpec{ClientCert: cert, ClientKey: key}}
+	return NewClient(cluster, user, namespace, token)
+}
+
+// NewClient returns a client of the vault of cluster in namespace with
+// token. It is configured from cluster and user only, the VAULT_ environment
+// variables are neither set nor read, so clients of several contexts can be
+// used side by side and a broken VAULT_CACERT does not break them.
+func NewClient(cluster *Cluster, user *User, namespace, token string) (*api.Client, error) {
+	if cluster == nil || cluster.Server == "" {
+		return nil, errors.New("cluster must have server address")
+	}
+	var client *api.Client
+	err := withoutVaultEnv(func() error {
+		apiCfg := api.DefaultConfig()
+		if apiCfg.Error != nil {
+			return apiCfg.Error
+		}
+		apiCfg.Address = cluster.Server
+		transport, ok := apiCfg.HttpClient.Transport.(*http.Transport)
+		if !ok {
+			return errors.New("unexpected transport of the vault client")
+		}
+		if err := configureTLS(transport.TLSClientConfig, cluster, user); err != nil {
+			return err
+		}
+		var err error
+		client, err = api.NewClient(apiCfg)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if namespace != "" {
-		apiClient.SetNamespace(namespace)
+	if token != "" {
+		client.SetToken(token)
+	} else {
+		client.ClearToken()
+	}
+	headers := client.Headers()
+	headers.Del("X-Vault-Namespace")
+	client.SetHeaders(headers)
+	if namespace != "" && namespace != "root" {
+		client.SetNamespace(namespace)
+	}
+	return client, nil
+}
+
+// vaultEnv are the VAULT_ environment variables the vault api configures
+// clients from, and fails on when they are invalid
+var vaultEnv = []string{
+	api.EnvVaultAddress, api.EnvVaultAgentAddr, api.EnvVaultMaxRetries,
+	api.EnvVaultCACert, api.EnvVaultCAPath, api.EnvVaultClientCert,
+	api.EnvVaultClientKey, api.EnvRateLimit, api.EnvVaultClientTimeout,
+	api.EnvVaultSkipVerify, api.EnvVaultSRVLookup, api.EnvVaultTLSServerName,
+}
+
+// vaultEnvMu serializes the clients created without vaultEnv
+var vaultEnvMu sync.Mutex
+
+// withoutVaultEnv runs f with the variables of vaultEnv unset and restores
+// them after. The vault api reads them in every api.NewClient, there is no
+// other way to create a client it does not configure.
+func withoutVaultEnv(f func() error) error {
+	vaultEnvMu.Lock()
+	defer vaultEnvMu.Unlock()
+	saved := map[string]string{}
+	for _, name := range vaultEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			saved[name] = value
+			os.Unsetenv(name)
+		}
+	}
+	defer func() {
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}()
+	return f()
+}
+
+// configureTLS configures tlsConfig with the certificate authority of
+// cluster and the client certificate of user, given as files or inline data
+func configureTLS(tlsConfig *tls.Config, cluster *Cluster, user *User) error {
+	tlsConfig.InsecureSkipVerify = cluster.InsecureSkipTLSVerify
+
+	ca, err := pemData(cluster.CertAuthData, cluster.CertAuth)
+	if err != nil {
+		return fmt.Errorf("certificate authority of cluster %s: %s", cluster.Name, err)
 	}
-	return apiClient, nil
+	if ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("certificate authority of cluster %s has no certificates", cluster.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if user == nil {
+		return nil
+	}
+	cert, err := pemData(user.ClientCertData, user.ClientCert)
+	if err != nil {
+		return fmt.Errorf("client certificate of user %s: %s", user.Name, err)
+	}
+	key, err := pemData(user.ClientKeyData, user.ClientKey)
+	if err != nil {
+		return fmt.Errorf("client key of user %s: %s", user.Name, err)
+	}
+	switch {
+	case cert != nil && key != nil:
+		clientCert, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return fmt.Errorf("client certificate of user %s: %s", user.Name, err)
+		}
+		// the client certificate is sent whatever authorities the
+		// server asks for, like the vault client does
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &clientCert, nil
+		}
+	case cert != nil || key != nil:
+		return fmt.Errorf("user %s needs both a client certificate and key", user.Name)
+	}
+	return nil
+}
+
+// pemData returns the pem of inline data, base64 encoded or not, or else
+// read from file, nil when neither is given
+func pemData(data, file string) ([]byte, error) {
+	switch {
+	case strings.Contains(data, "-----BEGIN"):
+		return []byte(data), nil
+	case data != "":
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	case file != "":
+		return ioutil.ReadFile(inventory.ExpandHomePath(file))
+	}
+	return nil, nil
 }
 
 // GetClientFromContext gets user/cluster/namespace info from context
-func (cfg *Config) GetClientFromContext(secretsvc secretservice.SecretService, configfile, contextName, namespace string) (*api.Client, error) {
+func (cfg *Config) GetClientFromContext(store Store, configfile, contextName, namespace string) (*api.Client, error) {
 	ctx := cfg.GetContextByName(contextName)
 
 	if ctx == nil {
@@ -39,13 +171,7 @@ func (cfg *Config) GetClientFromContext(secretsvc secretservice.SecretService, c
 
 	cluster := cfg.GetClusterByName(ctx.Cluster)
 	user := cfg.GetUserByName(ctx.User)
-	// if cluster == nil || cluster.Server == "" || cluster.CertAuth == "" {
-	// 	return nil, errors.New("cluster must have server address and CA cert")
-	// }
-	// if user == nil || user.ClientCert == "" || user.ClientKey == "" {
-	// 	return nil, errors.New("user must have cert and key")
-	// }
-	session, err := cfg.GetSession(secretsvc, configfile, contextName, false)
+	session, err := cfg.GetSession(store, configfile, contextName, false)
 	if err != nil {
 		return nil, err
 	}
@@ -53,29 +179,14 @@ func (cfg *Config) GetClientFromContext(secretsvc secretservice.SecretService, c
 	if namespace != "" {
 		ns = namespace
 	}
-	client, err := cfg.GetDefaultClient(ns, cluster.Server, session.Token, user.ClientCert, user.ClientKey, cluster.CertAuth)
-	// override environment variable
-	if ns != "" {
-		if ns == "root" {
-			ns = ""
-		}
-		client.SetNamespace(ns)
-	}
-	return client, nil
+	return NewClient(cluster, user, ns, session.Token)
 }
 
 // GetServiceFromContext gets user/cluster/namespace info from context
-func (cfg *Config) GetServiceFromContext(ctx *Context, configfile, namespace string) (secretservice.SecretService, error) {
+func (cfg *Config) GetServiceFromContext(ctx *Context, store Store, configfile, namespace string) (secretservice.SecretService, error) {
 	cluster := cfg.GetClusterByName(ctx.Cluster)
 	user := cfg.GetUserByName(ctx.User)
-	// if cluster == nil || cluster.Server == "" || cluster.CertAuth == "" {
-	// 	return nil, errors.New("cluster must have server address and CA cert")
-	// }
-	// if user == nil || user.ClientCert == "" || user.ClientKey == "" {
-	// 	return nil, errors.New("user must have cert and key")
-	// }
-	secretsvc := vault.NewVaultService()
-	session, err := cfg.GetSession(secretsvc, configfile, ctx.Name, false)
+	session, err := cfg.GetSession(store, configfile, ctx.Name, false)
 	if err != nil {
 		return nil, err
 	}
@@ -84,14 +195,11 @@ func (cfg *Config) GetServiceFromContext(ctx *Context, configfile, namespace str
 		ns = namespace
 	}
 	// This is going to be returned as a vaultstore.Store interface
-	defaultClient, err := cfg.GetDefaultClient(ns, cluster.Server, session.Token, user.ClientCert, user.ClientKey, cluster.CertAuth)
-	// override environment variable
-	if ns != "" {
-		if ns == "root" {
-			ns = ""
-		}
-		defaultClient.SetNamespace(ns)
+	defaultClient, err := NewClient(cluster, user, ns, session.Token)
+	if err != nil {
+		return nil, err
 	}
+	secretsvc := vault.NewVaultService()
 	secretsvc.SetClient(defaultClient)
 	return secretsvc, nil
 }
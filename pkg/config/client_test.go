@@ -0,0 +1,122 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// clientCertificate returns a self signed client certificate and key
+func clientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "me"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) != 1 || r.TLS.PeerCertificates[0].Subject.CommonName != "me" {
+			t.Errorf("expected the client certificate")
+		}
+		if token := r.Header.Get("X-Vault-Token"); token != "s.session" {
+			t.Errorf("unexpected token %q", token)
+		}
+		if ns := r.Header.Get("X-Vault-Namespace"); ns != "team" {
+			t.Errorf("unexpected namespace %q", ns)
+		}
+		w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// the environment neither configures the client nor is changed by it,
+	// invalid TLS files in it are not read
+	os.Setenv("VAULT_ADDR", "https://127.0.0.1:1")
+	os.Setenv("VAULT_NAMESPACE", "other")
+	os.Setenv("VAULT_CACERT", "/nonexistent/ca.pem")
+	os.Setenv("VAULT_CLIENT_CERT", "/nonexistent/cert.pem")
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_NAMESPACE")
+	defer os.Unsetenv("VAULT_CACERT")
+	defer os.Unsetenv("VAULT_CLIENT_CERT")
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	cert, key := clientCertificate(t)
+	cluster := &config.Cluster{Name: "local", ClusterSpec: config.ClusterSpec{Server: server.URL, CertAuthData: base64.StdEncoding.EncodeToString(ca)}}
+	user := &config.User{Name: "me", UserSpec: config.UserSpec{ClientCertData: base64.StdEncoding.EncodeToString(cert), ClientKeyData: string(key)}}
+
+	client, err := config.NewClient(cluster, user, "team", "s.session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.Logical().Read("secret/value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Data["value"] != "ok" {
+		t.Errorf("unexpected secret %v", secret.Data)
+	}
+	if os.Getenv("VAULT_ADDR") != "https://127.0.0.1:1" || os.Getenv("VAULT_CACERT") != "/nonexistent/ca.pem" || os.Getenv("VAULT_TOKEN") != "" {
+		t.Errorf("expected the environment to be unchanged")
+	}
+
+	// a root client sends no namespace
+	root, err := config.NewClient(cluster, user, "root", "s.session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns := root.Headers().Get("X-Vault-Namespace"); ns != "" {
+		t.Errorf("unexpected namespace %q", ns)
+	}
+
+	// without the certificate authority the server is not trusted
+	cluster.CertAuthData = ""
+	untrusted, err := config.NewClient(cluster, user, "team", "s.session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrusted.SetMaxRetries(0)
+	if _, err := untrusted.Logical().Read("secret/value"); err == nil {
+		t.Errorf("expected an untrusted server to fail")
+	}
+	cluster.InsecureSkipTLSVerify = true
+	insecure, err := config.NewClient(cluster, user, "team", "s.session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := insecure.Logical().Read("secret/value"); err != nil {
+		t.Errorf("expected insecure-skip-tls-verify to skip verification: %s", err)
+	}
+}
@@ -55,7 +55,7 @@ func (c *Config) SetCluster(name string,
 func (c *Config) DeleteCluster(name string) error {
 	if found := c.GetClusterByName(name); found != nil {
 		ctx := c.GetContextByName(c.CurrentContext)
-		if ctx.Cluster == name {
+		if ctx != nil && ctx.Cluster == name {
 			return errors.New("cannot delete cluster in current context")
 		}
 		clusters := []*Cluster{}
@@ -65,6 +65,7 @@ func (c *Config) DeleteCluster(name string) error {
 			}
 		}
 		c.Clusters = clusters
+		return nil
 	}
-	return nil
+	return errors.New("cluster not found")
 }
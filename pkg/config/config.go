@@ -1,19 +1,31 @@
 package config
 
 import (
-	"io/ioutil"
-
 	"gopkg.in/yaml.v2"
 )
 
-// SaveConfig writes yaml to a file
-func (c *Config) SaveConfig(filename string) error {
-	bytes, err := yaml.Marshal(c)
-	if err != nil {
-		return err
-	}
+// Store reads and updates config files, the configfile ConfigService is
+// the store of the commands
+type Store interface {
+	// Update reads the config file at path, applies fn and writes it back
+	// while holding the file lock
+	Update(path string, fn func(cfg *Config) error) error
+}
 
-	return ioutil.WriteFile(filename, bytes, 0644)
+// HasSecrets reports whether the config holds tokens, passwords, secret
+// ids or client key data
+func (c *Config) HasSecrets() bool {
+	for _, ctx := range c.Contexts {
+		if ctx.Session.Token != "" {
+			return true
+		}
+	}
+	for _, user := range c.Users {
+		if user.Password != "" || user.SecretID != "" || user.ClientKeyData != "" || user.Token != "" {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Config) String() string {
@@ -23,3 +35,35 @@ func (c *Config) String() string {
 	}
 	return string(bytes)
 }
+
+// redacted replaces the values of secret fields
+const redacted = "REDACTED"
+
+// Redacted returns a copy of the config with tokens, passwords, secret ids
+// and client key data replaced
+func (c *Config) Redacted() (*Config, error) {
+	bytes, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	cp := &Config{}
+	if err := yaml.Unmarshal(bytes, cp); err != nil {
+		return nil, err
+	}
+	for _, ctx := range cp.Contexts {
+		redact(&ctx.Session.Token)
+	}
+	for _, user := range cp.Users {
+		redact(&user.Password)
+		redact(&user.SecretID)
+		redact(&user.ClientKeyData)
+		redact(&user.Token)
+	}
+	return cp, nil
+}
+
+func redact(s *string) {
+	if *s != "" {
+		*s = redacted
+	}
+}
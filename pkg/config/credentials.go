@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CredentialStore keeps session tokens, passwords and secret ids out of the
+// config file. The file holds references like keyring:vaultcli/user/alice/password
+// to them, the part before the colon names the backend of the store.
+type CredentialStore interface {
+	Get(ref string) (string, error)
+	Set(ref, value string) error
+	Delete(ref string) error
+}
+
+// ErrCredentialNotFound is returned by a CredentialStore for a reference
+// it holds no secret for
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// secret returns value, or when ref is set the secret it references
+func (c *Config) secret(value, ref string) (string, error) {
+	if ref == "" {
+		return value, nil
+	}
+	if c.Credentials == nil {
+		return "", fmt.Errorf("no credential store to resolve %s", ref)
+	}
+	secret, err := c.Credentials.Get(ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// storeSecret moves *value to the credential store, to *ref or else to a
+// new reference of the backend when backend is not empty
+func (c *Config) storeSecret(value, ref *string, backend, key string) (bool, error) {
+	if *value == "" {
+		return false, nil
+	}
+	if *ref == "" {
+		if backend == "" {
+			return false, nil
+		}
+		*ref = backend + ":" + key
+	}
+	if c.Credentials == nil {
+		return false, fmt.Errorf("no credential store to save %s", *ref)
+	}
+	err := c.Credentials.Set(*ref, *value)
+	if err != nil {
+		return false, fmt.Errorf("unable to save %s: %s", *ref, err)
+	}
+	*value = ""
+	return true, nil
+}
+
+// StoreSecrets moves the plaintext passwords, secret ids and tokens of the
+// config to the credential store and returns how many it moved.
+// Secrets with a reference go to it, the others are left in place unless
+// backend names the backend to store them in.
+func (c *Config) StoreSecrets(backend string) (int, error) {
+	moved := 0
+	for _, user := range c.Users {
+		ok, err := c.storeSecret(&user.Password, &user.PasswordRef, backend, "vaultcli/user/"+user.Name+"/password")
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+		ok, err = c.storeSecret(&user.SecretID, &user.SecretIDRef, backend, "vaultcli/user/"+user.Name+"/secret-id")
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+		ok, err = c.storeSecret(&user.Token, &user.TokenRef, backend, "vaultcli/user/"+user.Name+"/token")
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+	}
+	for _, ctx := range c.Contexts {
+		ok, err := c.storeSecret(&ctx.Session.Token, &ctx.Session.TokenRef, backend, "vaultcli/context/"+ctx.Name+"/token")
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+	}
+	return moved, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/auth"
+	"github.com/ibm/vault-cli/pkg/inventory"
+)
+
+// ExecCredential is the json a credential plugin prints on stdout. It holds
+// one of a vault token, an approle role id and secret id, or a jwt to log
+// in with at the jwt auth method.
+type ExecCredential struct {
+	// Token is a vault token valid for TTL seconds
+	Token     string `json:"token,omitempty"`
+	TTL       int64  `json:"ttl,omitempty"`
+	Renewable bool   `json:"renewable,omitempty"`
+
+	RoleID   string `json:"role_id,omitempty"`
+	SecretID string `json:"secret_id,omitempty"`
+
+	// JWT logs in as Role
+	JWT  string `json:"jwt,omitempty"`
+	Role string `json:"role,omitempty"`
+}
+
+// Environment variables telling a credential plugin what it is run for
+const (
+	envExecServer    = "VAULTCLI_EXEC_SERVER"
+	envExecNamespace = "VAULTCLI_EXEC_NAMESPACE"
+	envExecUser      = "VAULTCLI_EXEC_USER"
+)
+
+// runExec runs the credential plugin of user and returns the credential it
+// printed. The plugin gets stdin and stderr of vault-cli so that it can
+// interact with the person running it.
+func runExec(user *User, server, namespace string) (*ExecCredential, error) {
+	cmd := exec.Command(inventory.ExpandHomePath(user.Exec.Command), user.Exec.Args...)
+	cmd.Env = append(os.Environ(),
+		envExecServer+"="+server,
+		envExecNamespace+"="+namespace,
+		envExecUser+"="+user.Name,
+	)
+	for _, env := range user.Exec.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("credential plugin %s of user %s failed: %s", user.Exec.Command, user.Name, err)
+	}
+
+	credential := &ExecCredential{}
+	decoder := json.NewDecoder(&stdout)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(credential)
+	if err != nil {
+		return nil, fmt.Errorf("credential plugin %s of user %s printed no credential: %s", user.Exec.Command, user.Name, err)
+	}
+	return credential, nil
+}
+
+// execLogin runs the credential plugin of user and returns the token it
+// printed, or logs in with the credentials it printed at the auth-path of
+// user
+func (cfg *Config) execLogin(client *api.Client, user *User, cluster *Cluster, namespace string) (*api.Secret, error) {
+	credential, err := runExec(user, cluster.Server, namespace)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case credential.Token != "":
+		return &api.Secret{
+			Auth: &api.SecretAuth{
+				ClientToken:   credential.Token,
+				LeaseDuration: int(credential.TTL),
+				Renewable:     credential.Renewable,
+			},
+		}, nil
+	case credential.RoleID != "":
+		method, path, err := user.loginPath("approle")
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, method, path, auth.Credentials{RoleID: credential.RoleID, SecretID: credential.SecretID})
+	case credential.JWT != "":
+		method, path, err := user.loginPath("jwt")
+		if err != nil {
+			return nil, err
+		}
+		return cfg.authenticate(client, method, path, auth.Credentials{Role: credential.Role, JWT: strings.TrimSpace(credential.JWT)})
+	}
+	return nil, fmt.Errorf("credential plugin %s of user %s printed no token, role_id or jwt", user.Exec.Command, user.Name)
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+func execConfig(script string) *config.Config {
+	return &config.Config{
+		Clusters: []*config.Cluster{{Name: "local", ClusterSpec: config.ClusterSpec{Server: "http://127.0.0.1:8200"}}},
+		Users: []*config.User{{Name: "sso", UserSpec: config.UserSpec{Exec: &config.ExecConfig{
+			Command: "sh",
+			Args:    []string{"-c", script},
+			Env:     []config.ExecEnvVar{{Name: "ROLE", Value: "deployer"}},
+		}}}},
+		Contexts: []*config.Context{{Name: "dev", ContextSpec: config.ContextSpec{Cluster: "local", User: "sso", Namespace: "team"}}},
+	}
+}
+
+func TestExecToken(t *testing.T) {
+	t.Parallel()
+
+	cfg := execConfig(`echo '{"token": "s.'$VAULTCLI_EXEC_NAMESPACE'", "ttl": 7200, "renewable": true}'`)
+	store := &configfakes.FakeConfigService{}
+	l := fakeLogins(cfg, nil, "approle", "jwt")
+	session, err := cfg.GetSession(store, "config.yaml", "dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Token != "s.team" || *session.LeaseDuration != 7200 || !*session.Renewable {
+		t.Errorf("unexpected session %+v", session)
+	}
+	if store.UpdateCallCount() != 1 {
+		t.Errorf("expected the session to be saved")
+	}
+	if l.LoginCallCount() != 0 {
+		t.Errorf("expected no login with a token")
+	}
+
+	// the saved session is used until it expires
+	if _, err := cfg.GetSession(store, "config.yaml", "dev", false); err != nil || store.UpdateCallCount() != 1 {
+		t.Errorf("expected the session to be reused, got %v", err)
+	}
+}
+
+func TestExecLogin(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		script   string
+		authPath string
+		method   string
+		path     string
+		err      string
+	}{
+		"approle":      {script: `echo '{"role_id": "r", "secret_id": "s"}'`, method: "approle", path: "approle"},
+		"jwt":          {script: `echo '{"jwt": "eyJ", "role": "'$ROLE'"}'`, method: "jwt", path: "jwt"},
+		"approle path": {script: `echo '{"role_id": "r", "secret_id": "s"}'`, authPath: "ci-approle", method: "approle", path: "ci-approle"},
+		"jwt path":     {script: `echo '{"jwt": "eyJ", "role": "'$ROLE'"}'`, authPath: "/gitlab/", method: "jwt", path: "gitlab"},
+		"empty":        {script: `echo '{}'`, err: "printed no token, role_id or jwt"},
+		"garbage":      {script: `echo hello`, err: "printed no credential"},
+		"unknown":      {script: `echo '{"password": "x"}'`, err: "printed no credential"},
+		"failing":      {script: `exit 3`, err: "credential plugin sh of user sso failed"},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cfg := execConfig(test.script)
+			cfg.Users[0].AuthPath = test.authPath
+			l := fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{ClientToken: "s.login", LeaseDuration: 3600}}, "approle", "jwt")
+
+			session, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.Token != "s.login" {
+				t.Errorf("unexpected session %+v", session)
+			}
+			if l.LoginCallCount() != 1 || l.methods[0] != test.method {
+				t.Errorf("expected one %s login, got %v", test.method, l.methods)
+			}
+			client, path := l.LoginArgsForCall(0)
+			if ns := client.Headers().Get("X-Vault-Namespace"); ns != "team" || path != test.path {
+				t.Errorf("unexpected login at %s in namespace %q", path, ns)
+			}
+			if credentials := l.credentials[0]; test.method == "jwt" && (credentials.Role != "deployer" || credentials.JWT != "eyJ") {
+				t.Errorf("unexpected jwt login %+v", credentials)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/inventory"
+)
+
+// DefaultKubernetesTokenPath is where kubernetes mounts the service account
+// token of a pod
+const DefaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// kubernetesJWT returns the service account token of the pod
+func kubernetesJWT(user *User) (string, error) {
+	path := user.Kubernetes.TokenPath
+	if path == "" {
+		path = DefaultKubernetesTokenPath
+	}
+	jwt, err := ioutil.ReadFile(inventory.ExpandHomePath(path))
+	if err != nil {
+		return "", fmt.Errorf("no service account token for user %s: %s", user.Name, err)
+	}
+	return strings.TrimSpace(string(jwt)), nil
+}
+
+// userJWT returns the jwt of the user, read from its file or environment
+// variable
+func userJWT(user *User) (string, error) {
+	j := user.JWT
+	var jwt string
+	switch {
+	case j.File != "":
+		data, err := ioutil.ReadFile(inventory.ExpandHomePath(j.File))
+		if err != nil {
+			return "", fmt.Errorf("no jwt for user %s: %s", user.Name, err)
+		}
+		jwt = string(data)
+	case j.Env != "":
+		jwt = os.Getenv(j.Env)
+	default:
+		return "", fmt.Errorf("user %s needs a jwt file or env", user.Name)
+	}
+	jwt = strings.TrimSpace(jwt)
+	if jwt == "" {
+		return "", fmt.Errorf("no jwt for user %s", user.Name)
+	}
+	return jwt, nil
+}
@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+// loginServer is a vault answering logins at path for role and jwt
+func loginServer(t *testing.T, path, role, jwt string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected body: %s", err)
+		}
+		if r.Method != "PUT" && r.Method != "POST" || r.URL.Path != path || body["role"] != role || body["jwt"] != jwt {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["bad login"]}`))
+			return
+		}
+		if ns := r.Header.Get("X-Vault-Namespace"); ns != "team" {
+			t.Errorf("unexpected namespace %q", ns)
+		}
+		w.Write([]byte(`{"auth":{"client_token":"s.login","lease_duration":3600,"renewable":true}}`))
+	}))
+}
+
+func loginConfig(server string, spec config.UserSpec) *config.Config {
+	cfg := tokenConfig(spec)
+	cfg.Clusters[0].Server = server
+	return cfg
+}
+
+func TestKubernetesLogin(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "kubernetes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenPath := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenPath, []byte("sa.jwt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := loginServer(t, "/v1/auth/k8s/login", "app", "sa.jwt")
+	defer server.Close()
+
+	cfg := loginConfig(server.URL, config.UserSpec{Kubernetes: &config.KubernetesAuth{Role: "app", TokenPath: tokenPath, Mount: "k8s"}})
+	session, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Token != "s.login" || session.Expires == nil {
+		t.Errorf("unexpected session %+v", session)
+	}
+
+	cfg = loginConfig(server.URL, config.UserSpec{Kubernetes: &config.KubernetesAuth{Role: "app", TokenPath: filepath.Join(dir, "missing")}})
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err == nil {
+		t.Errorf("expected an error without a service account token")
+	}
+}
+
+func TestJWTLogin(t *testing.T) {
+	t.Parallel()
+
+	server := loginServer(t, "/v1/auth/jwt/login", "ci", "ci.jwt")
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jwtFile := filepath.Join(dir, "jwt")
+	if err := ioutil.WriteFile(jwtFile, []byte("ci.jwt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("VAULTCLI_TEST_JWT", "ci.jwt")
+	defer os.Unsetenv("VAULTCLI_TEST_JWT")
+
+	for _, jwt := range []*config.JWTAuth{
+		{Role: "ci", File: jwtFile},
+		{Role: "ci", Env: "VAULTCLI_TEST_JWT"},
+	} {
+		cfg := loginConfig(server.URL, config.UserSpec{JWT: jwt})
+		session, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Token != "s.login" {
+			t.Errorf("unexpected session %+v", session)
+		}
+	}
+
+	cfg := loginConfig(server.URL, config.UserSpec{JWT: &config.JWTAuth{Role: "other", Env: "VAULTCLI_TEST_JWT"}})
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err == nil {
+		t.Errorf("expected an error for a rejected login")
+	}
+	cfg = loginConfig(server.URL, config.UserSpec{JWT: &config.JWTAuth{Role: "ci", Env: "VAULTCLI_TEST_UNSET"}})
+	if _, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false); err == nil {
+		t.Errorf("expected an error without a jwt")
+	}
+}
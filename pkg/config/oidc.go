@@ -0,0 +1,137 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/auth"
+)
+
+// DefaultOIDCListenAddress is where the identity provider redirects the
+// browser to, the address vault allows by default
+const DefaultOIDCListenAddress = "localhost:8250"
+
+// oidcCallbackPath is the path of the redirect uri
+const oidcCallbackPath = "/oidc/callback"
+
+// oidcTimeout is how long the login waits for the browser
+const oidcTimeout = 5 * time.Minute
+
+// OpenBrowser opens url in the browser of the user
+var OpenBrowser = openBrowser
+
+// openBrowser runs the command opening urls on this platform
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// oidcResult is what the callback listener got from vault
+type oidcResult struct {
+	secret *api.Secret
+	err    error
+}
+
+// oidcLogin logs in with the browser: the identity provider redirects it
+// to a local listener that exchanges the code for a token at vault
+func oidcLogin(client *api.Client, user *User) (*api.Secret, error) {
+	o := user.OIDC
+	mount := mountOr(o.Mount, "oidc")
+	address := o.ListenAddress
+	if address == "" {
+		address = DefaultOIDCListenAddress
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oidc listen address %s: %s", address, err)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for the oidc callback: %s", err)
+	}
+	defer listener.Close()
+	// the port is only known after listening on port 0
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	redirectURI := "http://" + net.JoinHostPort(host, port) + oidcCallbackPath
+
+	nonce, err := oidcNonce()
+	if err != nil {
+		return nil, err
+	}
+	authURL, err := auth.OIDCAuthURL(client, mount, o.Role, redirectURI, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan oidcResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result oidcResult
+		if e := query.Get("error"); e != "" {
+			result.err = fmt.Errorf("oidc login failed: %s %s", e, query.Get("error_description"))
+		} else {
+			result.secret, result.err = auth.OIDCCallback(client, mount, query.Get("state"), query.Get("code"), nonce)
+		}
+		// the query is not echoed to the browser, the cli gets the error
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Vault login failed, see vault-cli for the error.\n")
+		} else {
+			fmt.Fprintf(w, "Vault login succeeded, you can close this window.\n")
+		}
+		// the listener closes once the result is taken
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintf(os.Stderr, "Complete the login of user %s in your browser at:\n\n    %s\n\n", user.Name, authURL)
+	if !o.SkipBrowser {
+		if err := OpenBrowser(authURL); err != nil {
+			fmt.Fprintf(os.Stderr, "could not open the browser: %s\n", err)
+		}
+	}
+
+	select {
+	case result := <-results:
+		return result.secret, result.err
+	case <-time.After(oidcTimeout):
+		return nil, errors.New("timed out waiting for the oidc login in the browser")
+	}
+}
+
+// oidcNonce returns a random client nonce binding the callback to this
+// login
+func oidcNonce() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
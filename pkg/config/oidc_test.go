@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	// an identity provider redirecting the browser back with a code
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		redirect := query.Get("redirect_uri") + "?" + url.Values{"state": {query.Get("state")}, "code": {"idp-code"}}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}))
+	defer idp.Close()
+
+	var nonce string
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ns := r.Header.Get("X-Vault-Namespace"); ns != "team" {
+			t.Errorf("unexpected namespace %q", ns)
+		}
+		switch r.URL.Path {
+		case "/v1/auth/sso/oidc/auth_url":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %s", err)
+			}
+			if body["role"] != "dev" || body["client_nonce"] == "" {
+				t.Errorf("unexpected auth url request %v", body)
+			}
+			nonce = body["client_nonce"]
+			authURL := idp.URL + "/authorize?" + url.Values{"redirect_uri": {body["redirect_uri"]}, "state": {"st"}}.Encode()
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"auth_url": authURL}})
+		case "/v1/auth/sso/oidc/callback":
+			query := r.URL.Query()
+			if query.Get("state") != "st" || query.Get("code") != "idp-code" || query.Get("client_nonce") != nonce {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors":["bad callback"]}`))
+				return
+			}
+			w.Write([]byte(`{"auth":{"client_token":"s.oidc","lease_duration":3600,"renewable":true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vaultServer.Close()
+
+	// the browser follows the redirects to the callback listener
+	var opened string
+	config.OpenBrowser = func(authURL string) error {
+		opened = authURL
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				t.Errorf("browser: %s", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+
+	cfg := loginConfig(vaultServer.URL, config.UserSpec{OIDC: &config.OIDCAuth{Role: "dev", Mount: "sso", ListenAddress: "127.0.0.1:0"}})
+	store := &configfakes.FakeConfigService{}
+	session, err := cfg.GetSession(store, "config.yaml", "dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened == "" {
+		t.Errorf("expected the browser to be opened")
+	}
+	if session.Token != "s.oidc" || session.Expires == nil {
+		t.Errorf("unexpected session %+v", session)
+	}
+	if store.UpdateCallCount() != 1 {
+		t.Errorf("expected the session to be saved")
+	}
+}
+
+func TestOIDCLoginError(t *testing.T) {
+	// an identity provider redirecting the browser back with an error
+	description := "<script>alert(1)</script>"
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirect := r.URL.Query().Get("redirect_uri") + "?" + url.Values{"error": {"access_denied"}, "error_description": {description}}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}))
+	defer idp.Close()
+
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		authURL := idp.URL + "/authorize?" + url.Values{"redirect_uri": {body["redirect_uri"]}}.Encode()
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"auth_url": authURL}})
+	}))
+	defer vaultServer.Close()
+
+	page := make(chan string, 1)
+	config.OpenBrowser = func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				t.Errorf("browser: %s", err)
+				page <- ""
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := ioutil.ReadAll(resp.Body)
+			page <- resp.Header.Get("Content-Type") + "\n" + string(b)
+		}()
+		return nil
+	}
+
+	cfg := loginConfig(vaultServer.URL, config.UserSpec{OIDC: &config.OIDCAuth{Role: "dev", Mount: "sso", ListenAddress: "127.0.0.1:0"}})
+	_, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+	if err == nil || !strings.Contains(err.Error(), description) {
+		t.Errorf("expected the error of the identity provider, got %v", err)
+	}
+	// the browser gets plain text without the description
+	if p := <-page; !strings.HasPrefix(p, "text/plain") || strings.Contains(p, description) {
+		t.Errorf("unexpected callback page %q", p)
+	}
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/secretservice"
+)
+
+// sessionRetry is how long a session watcher waits before it tries to log
+// in again after a failed login
+const sessionRetry = time.Minute
+
+// sessionBackoff is how long a session watcher first waits before it logs
+// in again, doubling up to sessionRetry while sessions end as soon as they
+// start
+const sessionBackoff = time.Second
+
+// SessionWatcher keeps the session of a context valid during a long run
+type SessionWatcher struct {
+	cfg         *Config
+	store       Store
+	secretsvc   secretservice.SecretService
+	configfile  string
+	contextName string
+	logf        func(format string, args ...interface{})
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchSession watches the session of the named context, whose token the
+// client of secretsvc uses. Once the session reaches its expiry, which is
+// before the token expires, the token is renewed for as long as vault
+// allows, then the user logs in again with its auth method and the client
+// gets the new token. Every new lease is saved to the config file and
+// errors go to logf. Commands that end before the session expires never
+// renew, and a session without expiry, like that of a root token, is not
+// watched.
+func (cfg *Config) WatchSession(store Store, secretsvc secretservice.SecretService, configfile, contextName string, logf func(format string, args ...interface{})) (*SessionWatcher, error) {
+	ctx := cfg.GetContextByName(contextName)
+	if ctx == nil {
+		return nil, fmt.Errorf("context %s not found", contextName)
+	}
+	if secretsvc.GetClient() == nil {
+		return nil, errors.New("no vault client to watch the session of")
+	}
+	w := &SessionWatcher{
+		cfg:         cfg,
+		store:       store,
+		secretsvc:   secretsvc,
+		configfile:  configfile,
+		contextName: contextName,
+		logf:        logf,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if !expiring(ctx.Session) {
+		close(w.done)
+		return w, nil
+	}
+	go w.run(time.Until(time.Unix(*ctx.Session.Expires, 0)))
+	return w, nil
+}
+
+// expiring reports whether session has a lease that ends, a token with a
+// ttl of 0 never expires and cannot be renewed
+func expiring(session Session) bool {
+	if session.Expires == nil || *session.Expires <= 0 {
+		return false
+	}
+	return session.LeaseDuration == nil || *session.LeaseDuration > 0
+}
+
+// Stop stops watching the session
+func (w *SessionWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// run renews the session and logs in again until the watcher is stopped
+// or the new session no longer expires
+func (w *SessionWatcher) run(wait time.Duration) {
+	defer close(w.done)
+	if !w.sleep(wait) {
+		return
+	}
+	backoff := sessionBackoff
+	for {
+		started := time.Now()
+		stopped, err := w.watch()
+		if stopped {
+			return
+		}
+		if err != nil {
+			w.logf("renewing the session of context %s: %s", w.contextName, err)
+		}
+		// a session that lasted resets the backoff, one that ended at
+		// once doubles it
+		if time.Since(started) > sessionRetry {
+			backoff = sessionBackoff
+		}
+		if !w.sleep(backoff) {
+			return
+		}
+		if backoff *= 2; backoff > sessionRetry {
+			backoff = sessionRetry
+		}
+		for {
+			err := w.login()
+			if err == nil {
+				break
+			}
+			w.logf("logging in to context %s, retrying in %s: %s", w.contextName, sessionRetry, err)
+			if !w.sleep(sessionRetry) {
+				return
+			}
+		}
+		if ctx := w.cfg.GetContextByName(w.contextName); ctx == nil || !expiring(ctx.Session) {
+			return
+		}
+	}
+}
+
+// watch renews the token of the client until vault no longer extends it
+// and reports whether the watcher was stopped meanwhile
+func (w *SessionWatcher) watch() (bool, error) {
+	client, err := w.client()
+	if err != nil {
+		return false, err
+	}
+	self, err := client.Auth().Token().LookupSelf()
+	if err != nil {
+		return false, err
+	}
+	ttl, err := self.TokenTTL()
+	if err != nil {
+		return false, err
+	}
+	renewable, err := self.TokenIsRenewable()
+	if err != nil {
+		return false, err
+	}
+	watcher, err := client.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret: &api.Secret{Auth: &api.SecretAuth{
+			ClientToken:   client.Token(),
+			LeaseDuration: int(ttl.Seconds()),
+			Renewable:     renewable,
+		}},
+	})
+	if err != nil {
+		return false, err
+	}
+	go watcher.Start()
+	defer watcher.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return true, nil
+		case err := <-watcher.DoneCh():
+			return false, err
+		case renewal := <-watcher.RenewCh():
+			if renewal.Secret == nil || renewal.Secret.Auth == nil {
+				continue
+			}
+			if err := w.save(client.Token(), renewal.Secret.Auth); err != nil {
+				w.logf("saving the session of context %s: %s", w.contextName, err)
+			}
+		}
+	}
+}
+
+// client returns a client of its own for the token of the commands, in the
+// namespace the user logs in to, which the commands may change on theirs
+func (w *SessionWatcher) client() (*api.Client, error) {
+	ctx := w.cfg.GetContextByName(w.contextName)
+	if ctx == nil {
+		return nil, fmt.Errorf("context %s not found", w.contextName)
+	}
+	user := w.cfg.GetUserByName(ctx.User)
+	ns := ctx.Namespace
+	if user != nil && user.IgnoreNamespaceOnAuth {
+		ns = ""
+	}
+	return NewClient(w.cfg.GetClusterByName(ctx.Cluster), user, ns, w.secretsvc.GetClient().Token())
+}
+
+// save saves the renewed lease of token as the session of the context
+func (w *SessionWatcher) save(token string, auth *api.SecretAuth) error {
+	ctx := w.cfg.GetContextByName(w.contextName)
+	if ctx == nil {
+		return fmt.Errorf("context %s not found", w.contextName)
+	}
+	duration := int64(auth.LeaseDuration)
+	session := w.cfg.SetSession(token, &duration, &auth.Renewable, sessionSkew(duration))
+	session.TokenRef = ctx.Session.TokenRef
+	ctx.Session = *session
+	// the token stays where it is kept, only its lease changed
+	saved := *session
+	if user := w.cfg.GetUserByName(ctx.User); saved.TokenRef != "" || user != nil && user.IsTokenUser() {
+		saved.Token = ""
+	}
+	return saveSession(w.store, w.configfile, w.contextName, &saved)
+}
+
+// login logs in again and gives the client of the commands the new token
+func (w *SessionWatcher) login() error {
+	session, err := w.cfg.GetSession(w.store, w.configfile, w.contextName, true)
+	if err != nil {
+		return err
+	}
+	w.secretsvc.GetClient().SetToken(session.Token)
+	return nil
+}
+
+// sleep waits for d and reports whether the watcher is still running
+func (w *SessionWatcher) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-w.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+	"github.com/ibm/vault-cli/pkg/secretservice/fakes"
+)
+
+func TestWatchSession(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	renewed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Vault-Token")
+		switch {
+		case r.URL.Path == "/v1/auth/token/lookup-self" && token == "s.old":
+			w.Write([]byte(`{"data":{"ttl":2,"renewable":true}}`))
+		case r.URL.Path == "/v1/auth/token/lookup-self" && token == "s.new":
+			w.Write([]byte(`{"data":{"ttl":3600,"renewable":false}}`))
+		case r.URL.Path == "/v1/auth/token/renew-self" && token == "s.old":
+			mu.Lock()
+			renewed++
+			mu.Unlock()
+			// the token reached its max ttl, the lease is not extended
+			w.Write([]byte(`{"auth":{"client_token":"s.old","lease_duration":0,"renewable":true}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := loginConfig(server.URL, config.UserSpec{RoleID: "role", SecretID: "secret"})
+	expires := time.Now().Unix()
+	cfg.Contexts[0].Session = config.Session{Token: "s.old", Expires: &expires}
+
+	client, err := config.NewClient(cfg.Clusters[0], cfg.Users[0], "team", "s.old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &configfakes.FakeConfigService{}
+	secretsvc := &fakes.FakeSecretService{}
+	secretsvc.GetClientReturns(client)
+	fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{ClientToken: "s.new", LeaseDuration: 3600}}, "approle")
+
+	watcher, err := cfg.WatchSession(store, secretsvc, "config.yaml", "dev", t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for client.Token() != "s.new" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	watcher.Stop()
+
+	if token := client.Token(); token != "s.new" {
+		t.Fatalf("expected the client to log in again, got token %q", token)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if renewed == 0 {
+		t.Errorf("expected the token to be renewed")
+	}
+	if store.UpdateCallCount() < 2 {
+		t.Errorf("expected the renewed and the new session to be saved, got %d saves", store.UpdateCallCount())
+	}
+	if session := cfg.GetContextByName("dev").Session; session.Token != "s.new" || session.Expires == nil {
+		t.Errorf("unexpected session %+v", session)
+	}
+
+	if _, err := cfg.WatchSession(store, secretsvc, "config.yaml", "missing", t.Logf); err == nil {
+		t.Errorf("expected an error for a missing context")
+	}
+}
+
+func TestWatchSessionWithoutTTL(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		// a token with a ttl of 0, like a root token, never expires
+		w.Write([]byte(`{"data":{"ttl":0,"renewable":false}}`))
+	}))
+	defer server.Close()
+
+	cfg := loginConfig(server.URL, config.UserSpec{RoleID: "role", SecretID: "secret"})
+	client, err := config.NewClient(cfg.Clusters[0], cfg.Users[0], "team", "s.root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &configfakes.FakeConfigService{}
+	secretsvc := &fakes.FakeSecretService{}
+	secretsvc.GetClientReturns(client)
+	logins := fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{ClientToken: "s.new"}}, "approle")
+
+	// a session without expiry is not watched
+	zero := int64(0)
+	cfg.Contexts[0].Session = config.Session{Token: "s.root", LeaseDuration: &zero}
+	watcher, err := cfg.WatchSession(store, secretsvc, "config.yaml", "dev", t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	watcher.Stop()
+	logins.mu.Lock()
+	mu.Lock()
+	if requests != 0 || len(logins.methods) != 0 {
+		t.Errorf("expected no requests and logins, got %d requests and %d logins", requests, len(logins.methods))
+	}
+	mu.Unlock()
+	logins.mu.Unlock()
+
+	// a session that expires with a token of ttl 0 logs in once, the new
+	// session does not expire either
+	expires := time.Now().Unix()
+	cfg.Contexts[0].Session = config.Session{Token: "s.root", Expires: &expires}
+	watcher, err = cfg.WatchSession(store, secretsvc, "config.yaml", "dev", t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for client.Token() != "s.new" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(500 * time.Millisecond)
+	watcher.Stop()
+	logins.mu.Lock()
+	defer logins.mu.Unlock()
+	mu.Lock()
+	defer mu.Unlock()
+	if client.Token() != "s.new" || len(logins.methods) != 1 {
+		t.Errorf("expected one login, got %d and token %q", len(logins.methods), client.Token())
+	}
+	if requests > 2 {
+		t.Errorf("expected at most 2 requests, got %d", requests)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/hashicorp/vault/api"
-	"github.com/ibm/vault-cli/pkg/secretservice"
 )
 
 // SetSession Builds a session object
@@ -32,15 +31,43 @@ func (c *Config) SetSession(token string, duration *int64, renewable *bool, expi
 // vaultSessionExpireSkewFactor the amount of time to subtract from Expire to account for clock skew
 const vaultSessionExpireSkewFactor = int64(30 * 60)
 
-// GetSession will return an existing session or create a new one and save the config
-func (cfg *Config) GetSession(secretsvc secretservice.SecretService, configfile, contextName string, forceNewSession bool) (*Session, error) {
+// sessionSkew returns the skew of a session with a lease of duration
+// seconds, a short lived token expires halfway through its lease
+func sessionSkew(duration int64) int64 {
+	if duration < 2*vaultSessionExpireSkewFactor {
+		return duration / 2
+	}
+	return vaultSessionExpireSkewFactor
+}
+
+// GetSession will return an existing session or create a new one and save
+// it to the config file through store
+func (cfg *Config) GetSession(store Store, configfile, contextName string, forceNewSession bool) (*Session, error) {
 	for _, c := range cfg.Contexts {
 		if c.Name == contextName {
+			if user := cfg.GetUserByName(c.User); user != nil && user.IsTokenUser() {
+				cluster := cfg.GetClusterByName(c.Cluster)
+				if cluster == nil || cluster.Server == "" {
+					return nil, errors.New("cluster must have server address")
+				}
+				ns := c.Namespace
+				if user.IgnoreNamespaceOnAuth == true {
+					ns = ""
+				}
+				return cfg.tokenSession(store, configfile, c, user, cluster, ns)
+			}
 			now := time.Now().UTC().Unix()
 			if c.Session.Expires == nil {
 				zero := int64(0)
 				c.Session.Expires = &zero
 			}
+			if c.Session.Token == "" && c.Session.TokenRef != "" {
+				token, err := cfg.secret("", c.Session.TokenRef)
+				if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+					return nil, err
+				}
+				c.Session.Token = token
+			}
 
 			if forceNewSession || c.Session.Token == "" || now > *c.Session.Expires {
 				cluster := cfg.GetClusterByName(c.Cluster)
@@ -51,21 +78,21 @@ func (cfg *Config) GetSession(secretsvc secretservice.SecretService, configfile,
 				if user == nil {
 					return nil, errors.New("user must have cert and key")
 				}
-				var err error
-				var response *api.Secret
 				ns := c.Namespace
 				if user.IgnoreNamespaceOnAuth == true {
 					ns = ""
 				}
-				if user.ClientCert != "" {
-					response, err = secretsvc.CertLogin(ns, cluster.Server, "cert", user.ClientCert, user.ClientKey, cluster.CertAuth, cluster.InsecureSkipTLSVerify)
-				} else if user.Username != "" {
-					response, err = secretsvc.UserPassLogin(ns, cluster.Server, "userpass", user.Username, user.Password, cluster.CertAuth, cluster.InsecureSkipTLSVerify)
-				} else if user.RoleID != "" {
-					response, err = secretsvc.AppRoleLogin(ns, cluster.Server, "approle", user.RoleID, user.SecretID, cluster.CertAuth, cluster.InsecureSkipTLSVerify)
-
+				client, err := NewClient(cluster, user, ns, "")
+				if err != nil {
+					return nil, err
+				}
+				var response *api.Secret
+				if user.Exec != nil {
+					response, err = cfg.execLogin(client, user, cluster, ns)
+				} else if user.OIDC != nil {
+					response, err = oidcLogin(client, user)
 				} else {
-					return nil, fmt.Errorf("GetSession login requires credentials")
+					response, err = cfg.login(client, user)
 				}
 				if err != nil {
 					return nil, err
@@ -73,9 +100,20 @@ func (cfg *Config) GetSession(secretsvc secretservice.SecretService, configfile,
 
 				if response.Auth != nil {
 					duration := int64(response.Auth.LeaseDuration)
-					session := cfg.SetSession(response.Auth.ClientToken, &duration, &response.Auth.Renewable, vaultSessionExpireSkewFactor)
+					session := cfg.SetSession(response.Auth.ClientToken, &duration, &response.Auth.Renewable, sessionSkew(duration))
+					session.TokenRef = c.Session.TokenRef
 					c.Session = *session
-					cfg.SaveConfig(configfile)
+					// a session with a reference keeps its token in the
+					// credential store
+					saved := *session
+					_, err = cfg.storeSecret(&saved.Token, &saved.TokenRef, "", "")
+					if err != nil {
+						return nil, err
+					}
+					err = saveSession(store, configfile, contextName, &saved)
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 			return &c.Session, nil
@@ -85,13 +123,62 @@ func (cfg *Config) GetSession(secretsvc secretservice.SecretService, configfile,
 	return nil, errors.New("context not found")
 }
 
-// StopSession will return an existing session or create a new one and save the config
-func (cfg *Config) StopSession(configfile, contextName string) error {
+// StopSession clears the session of the named context and saves it to the
+// config file through store. A token kept in the credential store is
+// deleted, the reference stays for the next session.
+func (cfg *Config) StopSession(store Store, configfile, contextName string) error {
+	c := cfg.GetContextByName(contextName)
+	if c == nil {
+		return fmt.Errorf("context %s not found", contextName)
+	}
+	if ref := c.Session.TokenRef; ref != "" && cfg.Credentials != nil {
+		err := cfg.Credentials.Delete(ref)
+		if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+			return fmt.Errorf("unable to delete %s: %s", ref, err)
+		}
+	}
+	c.Session = Session{TokenRef: c.Session.TokenRef}
+	return saveSession(store, configfile, contextName, &c.Session)
+}
+
+// SessionClient returns a client with the token of the saved session of the
+// named context, in the namespace the user logs in to, without logging in.
+// The token is empty when there is no session or the user is a token user,
+// whose token is not the session's.
+func (cfg *Config) SessionClient(contextName string) (*api.Client, error) {
+	c := cfg.GetContextByName(contextName)
+	if c == nil {
+		return nil, fmt.Errorf("context %s not found", contextName)
+	}
+	user := cfg.GetUserByName(c.User)
+	ns := c.Namespace
+	if user != nil && user.IgnoreNamespaceOnAuth {
+		ns = ""
+	}
+	token := ""
+	if user == nil || !user.IsTokenUser() {
+		var err error
+		token, err = cfg.secret(c.Session.Token, c.Session.TokenRef)
+		if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+			return nil, err
+		}
+	}
+	return NewClient(cfg.GetClusterByName(c.Cluster), user, ns, token)
+}
 
-	for _, c := range cfg.Contexts {
-		if c.Name == contextName {
-			c.Session = Session{}
+// saveSession stores session as the session of the named context in the
+// config file, re-read so that changes another run saved meanwhile are kept
+func saveSession(store Store, configfile, contextName string, session *Session) error {
+	err := store.Update(configfile, func(current *Config) error {
+		ctx := current.GetContextByName(contextName)
+		if ctx == nil {
+			return fmt.Errorf("context %s not found", contextName)
 		}
+		ctx.Session = *session
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save session: %s", err)
 	}
 	return nil
 }
@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+// credentials is a credential store in memory
+type credentials map[string]string
+
+func (c credentials) Get(ref string) (string, error) {
+	value, ok := c[ref]
+	if !ok {
+		return "", config.ErrCredentialNotFound
+	}
+	return value, nil
+}
+
+func (c credentials) Set(ref, value string) error {
+	c[ref] = value
+	return nil
+}
+
+func (c credentials) Delete(ref string) error {
+	if _, ok := c[ref]; !ok {
+		return config.ErrCredentialNotFound
+	}
+	delete(c, ref)
+	return nil
+}
+
+func TestStopSession(t *testing.T) {
+	t.Parallel()
+
+	const ref = "file:vaultcli/context/dev/token"
+	store := credentials{ref: "s.session"}
+	cfg := tokenConfig(config.UserSpec{RoleID: "role"})
+	cfg.Credentials = store
+	expires := int64(1)
+	cfg.Contexts[0].Session = config.Session{TokenRef: ref, Expires: &expires}
+
+	client, err := cfg.SessionClient("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Token() != "s.session" || client.Headers().Get("X-Vault-Namespace") != "team" {
+		t.Errorf("unexpected client token %q, namespace %q", client.Token(), client.Headers().Get("X-Vault-Namespace"))
+	}
+
+	configsvc := &configfakes.FakeConfigService{}
+	if err := cfg.StopSession(configsvc, "config.yaml", "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store[ref]; ok {
+		t.Errorf("expected the token to be deleted from the credential store")
+	}
+	if configsvc.UpdateCallCount() != 1 {
+		t.Fatalf("expected the session to be saved")
+	}
+	saved := tokenConfig(config.UserSpec{})
+	saved.Contexts[0].Session = config.Session{Token: "s.other", Expires: &expires}
+	_, update := configsvc.UpdateArgsForCall(0)
+	if err := update(saved); err != nil {
+		t.Fatal(err)
+	}
+	if session := saved.Contexts[0].Session; session.Token != "" || session.Expires != nil || session.TokenRef != ref {
+		t.Errorf("unexpected saved session %+v", session)
+	}
+
+	// the token of a token user is not the session's
+	cfg = tokenConfig(config.UserSpec{Token: "s.user"})
+	cfg.Contexts[0].Session = config.Session{Token: "s.user"}
+	client, err = cfg.SessionClient("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Token() != "" {
+		t.Errorf("expected no token for a token user, got %q", client.Token())
+	}
+
+	if err := cfg.StopSession(configsvc, "config.yaml", "missing"); err == nil {
+		t.Errorf("expected an error for a missing context")
+	}
+}
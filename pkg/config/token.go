@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/ibm/vault-cli/pkg/auth"
+	"github.com/ibm/vault-cli/pkg/inventory"
+)
+
+// IsTokenUser reports whether user uses an existing token rather than
+// logging in
+func (u *User) IsTokenUser() bool {
+	return u.Token != "" || u.TokenRef != "" || u.TokenFile != "" || u.TokenEnv != "" || u.TokenHelper
+}
+
+// userToken returns the token of a token user from its source
+func (c *Config) userToken(user *User) (string, error) {
+	var token string
+	var err error
+	switch {
+	case user.Token != "" || user.TokenRef != "":
+		token, err = c.secret(user.Token, user.TokenRef)
+	case user.TokenFile != "":
+		var bytes []byte
+		bytes, err = ioutil.ReadFile(inventory.ExpandHomePath(user.TokenFile))
+		token = string(bytes)
+	case user.TokenEnv != "":
+		token = os.Getenv(user.TokenEnv)
+		if token == "" {
+			err = fmt.Errorf("%s is not set", user.TokenEnv)
+		}
+	case user.TokenHelper:
+		token, err = vaultCLIToken()
+	}
+	if err != nil {
+		return "", fmt.Errorf("no token for user %s: %s", user.Name, err)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token for user %s", user.Name)
+	}
+	return token, nil
+}
+
+// vaultCLIToken returns the token the vault CLI stores after a vault
+// login: from the token_helper of its config file, ~/.vault unless
+// VAULT_CONFIG_PATH is set, or else from ~/.vault-token
+func vaultCLIToken() (string, error) {
+	configPath := os.Getenv("VAULT_CONFIG_PATH")
+	if configPath == "" {
+		configPath = "~/.vault"
+	}
+	bytes, err := ioutil.ReadFile(inventory.ExpandHomePath(configPath))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	vaultConfig := struct {
+		TokenHelper string `hcl:"token_helper"`
+	}{}
+	if err == nil {
+		err = hcl.Decode(&vaultConfig, string(bytes))
+		if err != nil {
+			return "", fmt.Errorf("unable to parse %s: %s", configPath, err)
+		}
+	}
+
+	if vaultConfig.TokenHelper == "" {
+		bytes, err := ioutil.ReadFile(inventory.ExpandHomePath("~/.vault-token"))
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no ~/.vault-token, run vault login")
+		}
+		return string(bytes), err
+	}
+	cmd := exec.Command(inventory.ExpandHomePath(vaultConfig.TokenHelper), "get")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token helper %s failed: %s", vaultConfig.TokenHelper, err)
+	}
+	return string(out), nil
+}
+
+// tokenSession looks up the token of a token user, so that the session
+// has the real ttl of the token. The config file keeps the expiry of the
+// session but not the token, it stays where the user keeps it.
+func (c *Config) tokenSession(store Store, configfile string, ctx *Context, user *User, cluster *Cluster, namespace string) (*Session, error) {
+	token, err := c.userToken(user)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(cluster, user, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.authenticate(client, "token", "token", auth.Credentials{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("token of user %s: %s", user.Name, err)
+	}
+	if response.Auth == nil {
+		return nil, fmt.Errorf("token of user %s: the lookup returned no auth", user.Name)
+	}
+	duration := int64(response.Auth.LeaseDuration)
+	session := c.SetSession(token, &duration, &response.Auth.Renewable, 0)
+
+	// the expiry of a token only changes when the user has a new token, a
+	// token of another session is removed from the config file
+	saved := *session
+	saved.Token = ""
+	stale := ctx.Session.Token != "" && ctx.Session.Token != token || ctx.Session.TokenRef != ""
+	if stale || !sameExpiry(ctx.Session, saved) {
+		err = saveSession(store, configfile, ctx.Name, &saved)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx.Session = *session
+	return &ctx.Session, nil
+}
+
+// sameExpiry reports whether sessions a and b expire within a minute of
+// each other
+func sameExpiry(a, b Session) bool {
+	if a.Expires == nil || b.Expires == nil {
+		return a.Expires == nil && b.Expires == nil
+	}
+	diff := *a.Expires - *b.Expires
+	return diff > -60 && diff < 60
+}
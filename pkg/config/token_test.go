@@ -0,0 +1,148 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/config"
+	configfakes "github.com/ibm/vault-cli/pkg/configservice/fakes"
+)
+
+func tokenConfig(spec config.UserSpec) *config.Config {
+	return &config.Config{
+		Clusters: []*config.Cluster{{Name: "local", ClusterSpec: config.ClusterSpec{Server: "http://127.0.0.1:8200"}}},
+		Users:    []*config.User{{Name: "me", UserSpec: spec}},
+		Contexts: []*config.Context{{Name: "dev", ContextSpec: config.ContextSpec{Cluster: "local", User: "me", Namespace: "team"}}},
+	}
+}
+
+func TestTokenUser(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s.file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := tokenConfig(config.UserSpec{TokenFile: tokenFile})
+	store := &configfakes.FakeConfigService{}
+	l := fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{ClientToken: "s.file", LeaseDuration: 3600, Renewable: true}}, "token", "userpass", "approle", "cert")
+
+	session, err := cfg.GetSession(store, "config.yaml", "dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := l.LoginArgsForCall(0)
+	if ns := client.Headers().Get("X-Vault-Namespace"); ns != "team" || l.methods[0] != "token" || l.credentials[0].Token != "s.file" {
+		t.Errorf("unexpected lookup %s %s %+v", ns, l.methods[0], l.credentials[0])
+	}
+	expires := time.Now().Unix() + 3600
+	if session.Token != "s.file" || session.Expires == nil || *session.Expires < expires-5 || *session.Expires > expires || !*session.Renewable {
+		t.Errorf("unexpected session %+v", session)
+	}
+
+	if store.UpdateCallCount() != 1 {
+		t.Fatalf("expected the session to be saved")
+	}
+	_, update := store.UpdateArgsForCall(0)
+	saved := tokenConfig(config.UserSpec{})
+	if err := update(saved); err != nil {
+		t.Fatal(err)
+	}
+	if s := saved.GetContextByName("dev").Session; s.Token != "" || s.Expires == nil {
+		t.Errorf("expected the expiry to be saved without token, got %+v", s)
+	}
+
+	// the same token is looked up again, its expiry is not saved again
+	if _, err := cfg.GetSession(store, "config.yaml", "dev", false); err != nil {
+		t.Fatal(err)
+	}
+	if l.LoginCallCount() != 2 || store.UpdateCallCount() != 1 {
+		t.Errorf("unexpected lookups %d updates %d", l.LoginCallCount(), store.UpdateCallCount())
+	}
+	for _, method := range l.methods {
+		if method != "token" {
+			t.Errorf("expected no %s login for a token user", method)
+		}
+	}
+
+	l.LoginReturns(nil, errors.New("permission denied"))
+	if _, err := cfg.GetSession(store, "config.yaml", "dev", false); err == nil || !strings.Contains(err.Error(), "token of user me: permission denied") {
+		t.Errorf("expected lookup error, got %v", err)
+	}
+}
+
+func TestTokenUserSources(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		spec  config.UserSpec
+		token string
+		err   string
+	}{
+		"literal":   {spec: config.UserSpec{Token: "s.literal"}, token: "s.literal"},
+		"env":       {spec: config.UserSpec{TokenEnv: "PATH"}, token: os.Getenv("PATH")},
+		"unset env": {spec: config.UserSpec{TokenEnv: "VAULTCLI_TEST_UNSET"}, err: "no token for user me: VAULTCLI_TEST_UNSET is not set"},
+		"no file":   {spec: config.UserSpec{TokenFile: "/nonexistent/token"}, err: "no token for user me"},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cfg := tokenConfig(test.spec)
+			fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{}}, "token")
+			session, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.Token != test.token || session.Expires != nil {
+				t.Errorf("unexpected session %+v", session)
+			}
+		})
+	}
+}
+
+func TestTokenHelper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	helper := filepath.Join(dir, "helper")
+	if err := ioutil.WriteFile(helper, []byte("#!/bin/sh\n[ \"$1\" = get ] && echo s.helper\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	vaultConfig := filepath.Join(dir, "vault.hcl")
+	if err := ioutil.WriteFile(vaultConfig, []byte(`token_helper = "`+helper+`"`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("VAULT_CONFIG_PATH", os.Getenv("VAULT_CONFIG_PATH"))
+	os.Setenv("VAULT_CONFIG_PATH", vaultConfig)
+
+	cfg := tokenConfig(config.UserSpec{TokenHelper: true})
+	fakeLogins(cfg, &api.Secret{Auth: &api.SecretAuth{}}, "token")
+	session, err := cfg.GetSession(&configfakes.FakeConfigService{}, "config.yaml", "dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Token != "s.helper" {
+		t.Errorf("unexpected session %+v", session)
+	}
+}
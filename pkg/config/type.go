@@ -1,5 +1,7 @@
 package config
 
+import "github.com/ibm/vault-cli/pkg/auth"
+
 type ClusterSpec struct {
 	CertAuth              string `mapstructure:"certificate-authority" json:"certificate-authority" yaml:"certificate-authority"`
 	CertAuthData          string `mapstructure:"certificate-authority-data,omitempty" json:"certificate-authority-data,omitempty" yaml:"certificate-authority-data,omitempty"`
@@ -19,6 +21,9 @@ type Session struct {
 	LeaseDuration *int64 `mapstructure:"lease-duration,omitempty" json:"lease-duration,omitempty" yaml:"lease-duration,omitempty"`
 	Expires       *int64 `mapstructure:"expires,omitempty" json:"expires,omitempty" yaml:"expires,omitempty"`
 	Renewable     *bool  `mapstructure:"renewable,omitempty" json:"renewable,omitempty" yaml:"renewable,omitempty"`
+	// TokenRef references the token in the credential store, see
+	// CredentialStore
+	TokenRef string `mapstructure:"token-ref,omitempty" json:"token-ref,omitempty" yaml:"token-ref,omitempty"`
 }
 
 // ContextSpec consist of a user paired with a cluster, namespace
@@ -26,6 +31,7 @@ type ContextSpec struct {
 	Cluster       string `mapstructure:"cluster" json:"cluster" yaml:"cluster"`
 	InventoryPath string `mapstructure:"inventoryPath" json:"inventoryPath" yaml:"inventoryPath"`
 	Namespace     string `mapstructure:"namespace" json:"namespace" yaml:"namespace"`
+	OwnershipPath string `mapstructure:"ownershipPath,omitempty" json:"ownershipPath,omitempty" yaml:"ownershipPath,omitempty"`
 	Session       `mapstructure:"session,omitempty" json:"session,omitempty" yaml:"session,omitempty"`
 	User          string `mapstructure:"user" json:"user" yaml:"user"`
 }
@@ -48,6 +54,77 @@ type UserSpec struct {
 	RoleID                string `mapstructure:"roleID" json:"roleID" yaml:"roleID"`
 	SecretID              string `mapstructure:"secretID" json:"secretID" yaml:"secretID"`
 	IgnoreNamespaceOnAuth bool   `mapstructure:"ignore-namespace-on-auth" json:"ignore-namespace-on-auth" yaml:"ignore-namespace-on-auth"`
+	// AuthMethod is the method a cert, username or approle user logs in
+	// with, one of PasswordAuthMethods for a username, and AuthPath its
+	// mount, both default to the method of the credentials
+	AuthMethod string `mapstructure:"auth-method,omitempty" json:"auth-method,omitempty" yaml:"auth-method,omitempty"`
+	AuthPath   string `mapstructure:"auth-path,omitempty" json:"auth-path,omitempty" yaml:"auth-path,omitempty"`
+	// PasswordRef and SecretIDRef reference the password and secret id in
+	// the credential store, see CredentialStore
+	PasswordRef string `mapstructure:"password-ref,omitempty" json:"password-ref,omitempty" yaml:"password-ref,omitempty"`
+	SecretIDRef string `mapstructure:"secretID-ref,omitempty" json:"secretID-ref,omitempty" yaml:"secretID-ref,omitempty"`
+	// Token, TokenFile, TokenEnv and TokenHelper give the token of a user
+	// that does not log in, see tokenSession
+	Token       string `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
+	TokenRef    string `mapstructure:"token-ref,omitempty" json:"token-ref,omitempty" yaml:"token-ref,omitempty"`
+	TokenFile   string `mapstructure:"token-file,omitempty" json:"token-file,omitempty" yaml:"token-file,omitempty"`
+	TokenEnv    string `mapstructure:"token-env,omitempty" json:"token-env,omitempty" yaml:"token-env,omitempty"`
+	TokenHelper bool   `mapstructure:"token-helper,omitempty" json:"token-helper,omitempty" yaml:"token-helper,omitempty"`
+	// Exec runs a credential plugin instead of logging in with the
+	// credentials above
+	Exec *ExecConfig `mapstructure:"exec,omitempty" json:"exec,omitempty" yaml:"exec,omitempty"`
+	// Kubernetes and JWT log in with a service account token or a jwt
+	Kubernetes *KubernetesAuth `mapstructure:"kubernetes,omitempty" json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`
+	JWT        *JWTAuth        `mapstructure:"jwt,omitempty" json:"jwt,omitempty" yaml:"jwt,omitempty"`
+	// OIDC logs in with the browser of the user
+	OIDC *OIDCAuth `mapstructure:"oidc,omitempty" json:"oidc,omitempty" yaml:"oidc,omitempty"`
+}
+
+// KubernetesAuth logs in at the kubernetes auth method with the service
+// account token of the pod
+type KubernetesAuth struct {
+	Role string `mapstructure:"role" json:"role" yaml:"role"`
+	// TokenPath defaults to DefaultKubernetesTokenPath
+	TokenPath string `mapstructure:"token-path,omitempty" json:"token-path,omitempty" yaml:"token-path,omitempty"`
+	// Mount defaults to kubernetes
+	Mount string `mapstructure:"mount,omitempty" json:"mount,omitempty" yaml:"mount,omitempty"`
+}
+
+// JWTAuth logs in at the jwt auth method with a jwt read from a file or an
+// environment variable, like the id token of a CI job
+type JWTAuth struct {
+	Role string `mapstructure:"role" json:"role" yaml:"role"`
+	File string `mapstructure:"file,omitempty" json:"file,omitempty" yaml:"file,omitempty"`
+	Env  string `mapstructure:"env,omitempty" json:"env,omitempty" yaml:"env,omitempty"`
+	// Mount defaults to jwt
+	Mount string `mapstructure:"mount,omitempty" json:"mount,omitempty" yaml:"mount,omitempty"`
+}
+
+// OIDCAuth logs in at the oidc auth method, the identity provider
+// redirects the browser to a listener on ListenAddress
+type OIDCAuth struct {
+	// Role defaults to the default role of the mount
+	Role string `mapstructure:"role,omitempty" json:"role,omitempty" yaml:"role,omitempty"`
+	// Mount defaults to oidc
+	Mount string `mapstructure:"mount,omitempty" json:"mount,omitempty" yaml:"mount,omitempty"`
+	// ListenAddress defaults to DefaultOIDCListenAddress
+	ListenAddress string `mapstructure:"listen-address,omitempty" json:"listen-address,omitempty" yaml:"listen-address,omitempty"`
+	// SkipBrowser only prints the url to open
+	SkipBrowser bool `mapstructure:"skip-browser,omitempty" json:"skip-browser,omitempty" yaml:"skip-browser,omitempty"`
+}
+
+// ExecConfig is the command of a credential plugin, it prints an
+// ExecCredential on stdout
+type ExecConfig struct {
+	Command string       `mapstructure:"command" json:"command" yaml:"command"`
+	Args    []string     `mapstructure:"args,omitempty" json:"args,omitempty" yaml:"args,omitempty"`
+	Env     []ExecEnvVar `mapstructure:"env,omitempty" json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable set for a credential plugin
+type ExecEnvVar struct {
+	Name  string `mapstructure:"name" json:"name" yaml:"name"`
+	Value string `mapstructure:"value" json:"value" yaml:"value"`
 }
 
 // User is a user with a name
@@ -64,4 +141,11 @@ type Config struct {
 	Clusters       []*Cluster `mapstructure:"clusters" json:"clusters" yaml:"clusters"`
 	CurrentContext string     `mapstructure:"current-context" json:"current-context" yaml:"current-context"`
 	Users          []*User    `mapstructure:"users" json:"users" yaml:"users"`
+
+	// Credentials resolves the secret references of the config, nil when
+	// the config is used without credential store
+	Credentials CredentialStore `mapstructure:"-" json:"-" yaml:"-"`
+	// Authenticators logs in with the auth methods of the users, nil for
+	// auth.Default
+	Authenticators *auth.Registry `mapstructure:"-" json:"-" yaml:"-"`
 }
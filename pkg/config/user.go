@@ -1,6 +1,9 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // GetUserByName returns a named cluster
 func (c *Config) GetUserByName(name string) *User {
@@ -88,6 +91,33 @@ func (c *Config) SetUserPassUser(name string,
 	return found, nil
 }
 
+// SetUserAuthMethod sets the auth method a cert, username or approle user
+// logs in with and its mount when path is given, the default method of the
+// credentials is not stored
+func (c *Config) SetUserAuthMethod(name string,
+	method string,
+	path string,
+) (*User, error) {
+	found := c.GetUserByName(name)
+	if found == nil {
+		return nil, fmt.Errorf("SetUser: user %s not found", name)
+	}
+	switch {
+	case method == "cert" || method == "userpass" || method == "approle":
+		found.AuthMethod = ""
+	case IsPasswordAuthMethod(method):
+		found.AuthMethod = method
+	case path != "":
+		return nil, fmt.Errorf("SetUser: the auth path of a %s user is its mount", method)
+	default:
+		return found, nil
+	}
+	if path != "" {
+		found.AuthPath = path
+	}
+	return found, nil
+}
+
 // SetAppRoleUser will create a new user or update an existing user
 func (c *Config) SetAppRoleUser(name string,
 	roleID string,
@@ -121,12 +151,257 @@ func (c *Config) SetAppRoleUser(name string,
 	return found, nil
 }
 
+// SetExecUser will create a new user or update an existing user with a
+// credential plugin, args and env replace the existing ones unless nil
+func (c *Config) SetExecUser(name string,
+	command string,
+	args []string,
+	env []ExecEnvVar,
+) (*User, error) {
+	found := &User{}
+	if name == "" {
+		return nil, errors.New("SetUser: name cannot be empty")
+	}
+	if found = c.GetUserByName(name); found == nil {
+		if command == "" {
+			return nil, errors.New("SetUser: exec command cannot be empty")
+		}
+		newUser := User{
+			Name: name,
+			UserSpec: UserSpec{
+				Exec: &ExecConfig{
+					Command: command,
+					Args:    args,
+					Env:     env,
+				},
+			},
+		}
+		if c.Users == nil {
+			c.Users = []*User{}
+		}
+		c.Users = append(c.Users, &newUser)
+		return &newUser, nil
+	}
+	if found.Exec == nil {
+		if command == "" {
+			return nil, errors.New("SetUser: exec command cannot be empty")
+		}
+		found.Exec = &ExecConfig{}
+	}
+	if command != "" {
+		found.Exec.Command = command
+	}
+	if args != nil {
+		found.Exec.Args = args
+	}
+	if env != nil {
+		found.Exec.Env = env
+	}
+	return found, nil
+}
+
+// SetTokenUser will create a new user or update an existing user with an
+// existing token. The token is given, read from tokenFile, from the
+// environment variable tokenEnv or from the vault CLI when helper is set,
+// the source given replaces the previous one.
+func (c *Config) SetTokenUser(name string,
+	token string,
+	tokenFile string,
+	tokenEnv string,
+	helper bool,
+) (*User, error) {
+	found := &User{}
+	if name == "" {
+		return nil, errors.New("SetUser: name cannot be empty")
+	}
+	given := 0
+	for _, source := range []bool{token != "", tokenFile != "", tokenEnv != "", helper} {
+		if source {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, errors.New("SetUser: only one of token, token file, token env and token helper can be given")
+	}
+	if found = c.GetUserByName(name); found == nil {
+		if given == 0 {
+			return nil, errors.New("SetUser: token user needs a token, token file, token env or token helper")
+		}
+		newUser := User{
+			Name: name,
+			UserSpec: UserSpec{
+				Token:       token,
+				TokenFile:   tokenFile,
+				TokenEnv:    tokenEnv,
+				TokenHelper: helper,
+			},
+		}
+		if c.Users == nil {
+			c.Users = []*User{}
+		}
+		c.Users = append(c.Users, &newUser)
+		return &newUser, nil
+	}
+	if given == 0 {
+		if !found.IsTokenUser() {
+			return nil, errors.New("SetUser: token user needs a token, token file, token env or token helper")
+		}
+		return found, nil
+	}
+	// a new token replaces a token in the credential store
+	if token == "" {
+		found.TokenRef = ""
+	}
+	found.Token, found.TokenFile, found.TokenEnv, found.TokenHelper = token, tokenFile, tokenEnv, helper
+	return found, nil
+}
+
+// SetKubernetesUser will create a new user or update an existing user
+// logging in with a kubernetes service account token
+func (c *Config) SetKubernetesUser(name string,
+	role string,
+	tokenPath string,
+	mount string,
+) (*User, error) {
+	found := &User{}
+	if name == "" {
+		return nil, errors.New("SetUser: name cannot be empty")
+	}
+	if found = c.GetUserByName(name); found == nil {
+		if role == "" {
+			return nil, errors.New("SetUser: kubernetes role cannot be empty")
+		}
+		newUser := User{
+			Name: name,
+			UserSpec: UserSpec{
+				Kubernetes: &KubernetesAuth{
+					Role:      role,
+					TokenPath: tokenPath,
+					Mount:     mount,
+				},
+			},
+		}
+		if c.Users == nil {
+			c.Users = []*User{}
+		}
+		c.Users = append(c.Users, &newUser)
+		return &newUser, nil
+	}
+	if found.Kubernetes == nil {
+		if role == "" {
+			return nil, errors.New("SetUser: kubernetes role cannot be empty")
+		}
+		found.Kubernetes = &KubernetesAuth{}
+	}
+	if role != "" {
+		found.Kubernetes.Role = role
+	}
+	if tokenPath != "" {
+		found.Kubernetes.TokenPath = tokenPath
+	}
+	if mount != "" {
+		found.Kubernetes.Mount = mount
+	}
+	return found, nil
+}
+
+// SetJWTUser will create a new user or update an existing user logging in
+// with a jwt read from file or the environment variable env, the source
+// given replaces the previous one
+func (c *Config) SetJWTUser(name string,
+	role string,
+	file string,
+	env string,
+	mount string,
+) (*User, error) {
+	found := &User{}
+	if name == "" {
+		return nil, errors.New("SetUser: name cannot be empty")
+	}
+	if file != "" && env != "" {
+		return nil, errors.New("SetUser: only one of jwt file and jwt env can be given")
+	}
+	if found = c.GetUserByName(name); found == nil || found.JWT == nil {
+		if role == "" || file == "" && env == "" {
+			return nil, errors.New("SetUser: jwt user needs a role and a jwt file or env")
+		}
+	}
+	if found == nil {
+		newUser := User{
+			Name: name,
+			UserSpec: UserSpec{
+				JWT: &JWTAuth{
+					Role:  role,
+					File:  file,
+					Env:   env,
+					Mount: mount,
+				},
+			},
+		}
+		if c.Users == nil {
+			c.Users = []*User{}
+		}
+		c.Users = append(c.Users, &newUser)
+		return &newUser, nil
+	}
+	if found.JWT == nil {
+		found.JWT = &JWTAuth{}
+	}
+	if role != "" {
+		found.JWT.Role = role
+	}
+	if file != "" || env != "" {
+		found.JWT.File, found.JWT.Env = file, env
+	}
+	if mount != "" {
+		found.JWT.Mount = mount
+	}
+	return found, nil
+}
+
+// SetOIDCUser will create a new user or update an existing user logging in
+// with oidc in the browser, skipBrowser is nil when not given
+func (c *Config) SetOIDCUser(name string,
+	role string,
+	mount string,
+	listenAddress string,
+	skipBrowser *bool,
+) (*User, error) {
+	found := &User{}
+	if name == "" {
+		return nil, errors.New("SetUser: name cannot be empty")
+	}
+	if found = c.GetUserByName(name); found == nil {
+		found = &User{Name: name}
+		if c.Users == nil {
+			c.Users = []*User{}
+		}
+		c.Users = append(c.Users, found)
+	}
+	if found.OIDC == nil {
+		found.OIDC = &OIDCAuth{}
+	}
+	if role != "" {
+		found.OIDC.Role = role
+	}
+	if mount != "" {
+		found.OIDC.Mount = mount
+	}
+	if listenAddress != "" {
+		found.OIDC.ListenAddress = listenAddress
+	}
+	if skipBrowser != nil {
+		found.OIDC.SkipBrowser = *skipBrowser
+	}
+	return found, nil
+}
+
 // DeleteUser will delete the named cluster
 func (c *Config) DeleteUser(name string) error {
 	if found := c.GetUserByName(name); found != nil {
 		// should check if this user is in the current user
 		ctx := c.GetContextByName(c.CurrentContext)
-		if ctx.User == name {
+		if ctx != nil && ctx.User == name {
 			return errors.New("cannot delete user in current context")
 		}
 		users := []*User{}
@@ -1,7 +1,10 @@
 package configfile
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -18,7 +21,17 @@ func NewConfigFileService() configservice.ConfigService {
 	return &configfile{}
 }
 
+// Read reads the config file at path, a list of files is merged, see
+// merge.go. The default config is returned when no file exists.
 func (cf *configfile) Read(path string) (*config.Config, error) {
+	files := splitPath(path)
+	if len(files) > 1 {
+		cfgs, err := readFiles(files)
+		if err != nil {
+			return nil, err
+		}
+		return merge(cfgs)
+	}
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil && strings.Contains(err.Error(), "no such file") {
 		bytes = getDefaultConfig()
@@ -33,10 +46,148 @@ func (cf *configfile) Read(path string) (*config.Config, error) {
 	return &c, nil
 }
 
+// Write replaces the config file at path with cfg while holding the file
+// lock, see writeFile. With a list of files each file gets its part of cfg.
 func (cf *configfile) Write(path string, cfg *config.Config) error {
+	if len(splitPath(path)) > 1 {
+		return cf.Update(path, func(current *config.Config) error {
+			*current = *cfg
+			return nil
+		})
+	}
+	unlock, err := lockFiles([]string{path})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFile(path, cfg)
+}
+
+// Update re-reads the config file at path while holding the file lock,
+// applies fn and writes the result, so that concurrent runs refreshing
+// their sessions do not drop each other's changes. With a list of files
+// only the files defining what fn changed are written.
+func (cf *configfile) Update(path string, fn func(cfg *config.Config) error) error {
+	files := splitPath(path)
+	unlock, err := lockFiles(files)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if len(files) == 1 {
+		cfg, err := cf.Read(path)
+		if err != nil {
+			return err
+		}
+		err = fn(cfg)
+		if err != nil {
+			return err
+		}
+		return writeFile(path, cfg)
+	}
+
+	cfgs, err := readFiles(files)
+	if err != nil {
+		return err
+	}
+	cfg, err := merge(cfgs)
+	if err != nil {
+		return err
+	}
+	err = fn(cfg)
+	if err != nil {
+		return err
+	}
+	changed, err := split(cfgs, cfg)
+	if err != nil {
+		return err
+	}
+	for i, c := range changed {
+		if c == nil {
+			continue
+		}
+		err = writeFile(files[i], c)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// lockFiles locks each of files in order and returns the function releasing
+// the locks. A file whose lock cannot be created is not locked, it cannot
+// be written either.
+func lockFiles(files []string) (func(), error) {
+	unlocks := []func(){}
+	unlock := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, f := range files {
+		u, err := lockFile(f)
+		if os.IsPermission(err) || os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			unlock()
+			return nil, fmt.Errorf("unable to lock %s: %s", f, err)
+		}
+		unlocks = append(unlocks, u)
+	}
+	return unlock, nil
+}
+
+// writeFile backs the existing file up to path.bak and replaces it by
+// renaming a temporary file written next to it, a config holding secrets
+// is only readable by the owner
+func writeFile(path string, cfg *config.Config) error {
+	bytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	var mode os.FileMode = 0644
+	if cfg.HasSecrets() {
+		mode = 0600
+	}
+
+	previous, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		// the previous file may hold secrets the new one no longer has
+		err = writeAtomic(path+".bak", previous, 0600)
+		if err != nil {
+			return fmt.Errorf("unable to back up %s: %s", path, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	return writeAtomic(path, bytes, mode)
+}
+
+// writeAtomic writes data to a temporary file in the directory of path and
+// renames it to path
+func writeAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = tmp.Chmod(mode)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 func getDefaultConfig() []byte {
 	bytes := []byte(`apiVersion: v1
 kind: Config
@@ -0,0 +1,231 @@
+package configfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/ibm/vault-cli/pkg/configservice/configfile"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "configfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	svc := configfile.NewConfigFileService()
+
+	cfg, err := svc.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the default contexts keep no ownership index unless asked to
+	for _, ctx := range cfg.Contexts {
+		if ctx.OwnershipPath != "" {
+			t.Errorf("unexpected ownership path %s of default context %s", ctx.OwnershipPath, ctx.Name)
+		}
+	}
+	cfg.GetContextByName("ns-test").Session.Token = ""
+	cfg.GetContextByName("tpl-test").Session.Token = ""
+	if err := svc.Write(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, path, 0644)
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("expected no backup of a new file, got %v", err)
+	}
+
+	cfg.GetContextByName("ns-test").Session.Token = "s.token"
+	if err := svc.Write(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, path, 0600)
+	assertMode(t, path+".bak", 0600)
+	backup, err := svc.Read(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := backup.GetContextByName("ns-test").Session.Token; token != "" {
+		t.Errorf("expected the previous file in the backup, got token %q", token)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		switch f.Name() {
+		case "config.yaml", "config.yaml.bak", "config.yaml.lock":
+		default:
+			t.Errorf("unexpected file %s left behind", f.Name())
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "configfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	svc := configfile.NewConfigFileService()
+
+	// concurrent updates each see the changes of the ones before them
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := svc.Update(path, func(cfg *config.Config) error {
+				_, err := cfg.SetCluster(name, "", "", false, "http://"+name)
+				return err
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	cfg, err := svc.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if cfg.GetClusterByName(name) == nil {
+			t.Errorf("expected cluster %s to be kept", name)
+		}
+	}
+	assertMode(t, path, 0600)
+}
+
+func TestMergedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "configfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	personal := filepath.Join(dir, "personal.yaml")
+	shared := filepath.Join(dir, "shared.yaml")
+	writeYaml(t, personal, `contexts:
+- name: dev
+  context:
+    cluster: prod
+    user: me
+users:
+- name: me
+  user:
+    username: me
+clusters:
+- name: prod
+  cluster:
+    server: http://personal
+`)
+	writeYaml(t, shared, `current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: deployer
+clusters:
+- name: prod
+  cluster:
+    server: http://shared
+- name: test
+  cluster:
+    server: http://test
+`)
+	sharedBefore, err := ioutil.ReadFile(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := personal + string(os.PathListSeparator) + shared
+	svc := configfile.NewConfigFileService()
+
+	cfg, err := svc.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Clusters) != 2 || cfg.GetClusterByName("prod").Server != "http://personal" {
+		t.Errorf("expected the first definition of prod to win, got %v", cfg.Clusters)
+	}
+	if cfg.CurrentContext != "prod" || len(cfg.Contexts) != 2 {
+		t.Errorf("unexpected merge %s", cfg)
+	}
+
+	// the session goes to the file defining the context, a new cluster to
+	// the first file, the shared file is left alone
+	err = svc.Update(path, func(cfg *config.Config) error {
+		cfg.GetContextByName("dev").Session.Token = "s.token"
+		_, err := cfg.SetCluster("new", "", "", false, "http://new")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := svc.Read(personal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.GetContextByName("dev").Session.Token != "s.token" || p.GetClusterByName("new") == nil || p.GetContextByName("prod") != nil {
+		t.Errorf("unexpected personal file %s", p)
+	}
+	sharedAfter, err := ioutil.ReadFile(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(sharedAfter) != string(sharedBefore) {
+		t.Errorf("expected the shared file not to be written, got %s", sharedAfter)
+	}
+
+	err = svc.Update(path, func(cfg *config.Config) error {
+		cfg.GetContextByName("prod").Session.Token = "s.prod"
+		cfg.CurrentContext = "dev"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := svc.Read(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetContextByName("prod").Session.Token != "s.prod" || s.CurrentContext != "dev" {
+		t.Errorf("unexpected shared file %s", s)
+	}
+	if s.GetClusterByName("prod").Server != "http://shared" {
+		t.Errorf("expected the shadowed cluster to be kept, got %s", s)
+	}
+}
+
+func writeYaml(t *testing.T, path, content string) {
+	t.Helper()
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertMode(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("expected %s to have mode %o, got %o", filepath.Base(path), mode, info.Mode().Perm())
+	}
+}
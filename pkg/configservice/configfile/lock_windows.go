@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package configfile
+
+// lockFile does not lock on windows, writes are still atomic
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,230 @@
+package configfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// A config path can be a list of files like KUBECONFIG, separated by ":"
+// or ";" on windows. The files are merged in order, the first definition of
+// a named cluster, user or context and the first current-context win.
+// Changes are written back to the file defining what changed, new clusters,
+// users and contexts go to the first file that exists, or the last file
+// when none does.
+
+// splitPath returns the files of a config path, without empty entries and
+// duplicates
+func splitPath(path string) []string {
+	files := []string{}
+	seen := map[string]bool{}
+	for _, f := range filepath.SplitList(path) {
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		files = append(files, path)
+	}
+	return files
+}
+
+// readFiles reads each of files, nil for the ones that do not exist
+func readFiles(files []string) ([]*config.Config, error) {
+	cfgs := make([]*config.Config, len(files))
+	for i, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		c := config.Config{}
+		err = yaml.Unmarshal(data, &c)
+		if err != nil {
+			return nil, err
+		}
+		cfgs[i] = &c
+	}
+	return cfgs, nil
+}
+
+// merge returns a copy of cfgs merged in order, the default config when
+// none of them exists
+func merge(cfgs []*config.Config) (*config.Config, error) {
+	merged := &config.Config{}
+	found := false
+	for _, c := range cfgs {
+		if c == nil {
+			continue
+		}
+		found = true
+		if merged.APIVersion == "" {
+			merged.APIVersion = c.APIVersion
+		}
+		if merged.Kind == "" {
+			merged.Kind = c.Kind
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = c.CurrentContext
+		}
+		for _, cluster := range c.Clusters {
+			if merged.GetClusterByName(cluster.Name) == nil {
+				merged.Clusters = append(merged.Clusters, cluster)
+			}
+		}
+		for _, user := range c.Users {
+			if merged.GetUserByName(user.Name) == nil {
+				merged.Users = append(merged.Users, user)
+			}
+		}
+		for _, ctx := range c.Contexts {
+			if merged.GetContextByName(ctx.Name) == nil {
+				merged.Contexts = append(merged.Contexts, ctx)
+			}
+		}
+	}
+	if !found {
+		merged = &config.Config{}
+		err := yaml.Unmarshal(getDefaultConfig(), merged)
+		if err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+	// the merged config must not share clusters, users and contexts with
+	// cfgs, split compares them
+	return clone(merged)
+}
+
+// split returns the content of each of files for the merged config cfg,
+// nil for the files that do not change
+func split(cfgs []*config.Config, cfg *config.Config) ([]*config.Config, error) {
+	target := len(cfgs) - 1
+	currentOwner := -1
+	clusterOwner, userOwner, contextOwner := map[string]int{}, map[string]int{}, map[string]int{}
+	for i := len(cfgs) - 1; i >= 0; i-- {
+		c := cfgs[i]
+		if c == nil {
+			continue
+		}
+		target = i
+		if c.CurrentContext != "" {
+			currentOwner = i
+		}
+		for _, cluster := range c.Clusters {
+			clusterOwner[cluster.Name] = i
+		}
+		for _, user := range c.Users {
+			userOwner[user.Name] = i
+		}
+		for _, ctx := range c.Contexts {
+			contextOwner[ctx.Name] = i
+		}
+	}
+	if currentOwner == -1 {
+		currentOwner = target
+	}
+
+	out := make([]*config.Config, len(cfgs))
+	for i, c := range cfgs {
+		if c == nil {
+			c = &config.Config{APIVersion: cfg.APIVersion, Kind: cfg.Kind}
+		}
+		n := &config.Config{APIVersion: c.APIVersion, Kind: c.Kind, CurrentContext: c.CurrentContext}
+		if i == currentOwner {
+			n.CurrentContext = cfg.CurrentContext
+		}
+
+		// shadowed definitions are kept, the ones deleted from cfg are
+		// only removed from the file defining them
+		for _, cluster := range c.Clusters {
+			switch found := cfg.GetClusterByName(cluster.Name); {
+			case clusterOwner[cluster.Name] != i:
+				n.Clusters = append(n.Clusters, cluster)
+			case found != nil:
+				n.Clusters = append(n.Clusters, found)
+			}
+		}
+		for _, user := range c.Users {
+			switch found := cfg.GetUserByName(user.Name); {
+			case userOwner[user.Name] != i:
+				n.Users = append(n.Users, user)
+			case found != nil:
+				n.Users = append(n.Users, found)
+			}
+		}
+		for _, ctx := range c.Contexts {
+			switch found := cfg.GetContextByName(ctx.Name); {
+			case contextOwner[ctx.Name] != i:
+				n.Contexts = append(n.Contexts, ctx)
+			case found != nil:
+				n.Contexts = append(n.Contexts, found)
+			}
+		}
+		if i == target {
+			for _, cluster := range cfg.Clusters {
+				if _, ok := clusterOwner[cluster.Name]; !ok {
+					n.Clusters = append(n.Clusters, cluster)
+				}
+			}
+			for _, user := range cfg.Users {
+				if _, ok := userOwner[user.Name]; !ok {
+					n.Users = append(n.Users, user)
+				}
+			}
+			for _, ctx := range cfg.Contexts {
+				if _, ok := contextOwner[ctx.Name]; !ok {
+					n.Contexts = append(n.Contexts, ctx)
+				}
+			}
+		}
+
+		changed, err := differ(cfgs[i], n)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			out[i] = n
+		}
+	}
+	return out, nil
+}
+
+// differ reports whether n has to be written over the file content c, nil
+// when the file does not exist
+func differ(c, n *config.Config) (bool, error) {
+	if c == nil {
+		return len(n.Clusters) > 0 || len(n.Users) > 0 || len(n.Contexts) > 0 || n.CurrentContext != "", nil
+	}
+	before, err := yaml.Marshal(c)
+	if err != nil {
+		return false, err
+	}
+	after, err := yaml.Marshal(n)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(before, after), nil
+}
+
+// clone returns a deep copy of cfg
+func clone(cfg *config.Config) (*config.Config, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := &config.Config{}
+	err = yaml.Unmarshal(data, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
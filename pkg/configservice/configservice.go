@@ -9,4 +9,5 @@ import (
 type ConfigService interface {
 	Read(path string) (*config.Config, error)
 	Write(path string, cfg *config.Config) error
+	Update(path string, fn func(cfg *config.Config) error) error
 }
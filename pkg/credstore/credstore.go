@@ -0,0 +1,112 @@
+// Package credstore implements the config.CredentialStore of vault-cli. A
+// reference like keyring:vaultcli/user/alice/password names the backend
+// before the colon and the key of the secret after it:
+//
+//	keyring  the secret service of the OS: secret-tool on linux, security
+//	         on macOS. Where it is not reachable, e.g. headless, secrets go
+//	         to the encrypted file.
+//	file     a local file encrypted with a passphrase, taken from the
+//	         VAULTCLI_CREDENTIALS_PASSPHRASE environment variable or asked.
+package credstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+const (
+	// EnvPassphrase is the environment variable holding the passphrase of
+	// the encrypted file
+	EnvPassphrase = "VAULTCLI_CREDENTIALS_PASSPHRASE"
+	// FileName is the name of the encrypted file in the config directory
+	FileName = "credentials.enc"
+)
+
+// backend keeps secrets by key
+type backend interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Store dispatches references to the backend they name
+type Store struct {
+	backends map[string]backend
+}
+
+// New returns a credential store keeping its encrypted file in dir, ask
+// asks for its passphrase when EnvPassphrase is not set
+func New(dir string, ask func() (string, error)) *Store {
+	file := &fileBackend{
+		path: filepath.Join(dir, FileName),
+		passphrase: func() (string, error) {
+			if p := os.Getenv(EnvPassphrase); p != "" {
+				return p, nil
+			}
+			return ask()
+		},
+	}
+	return &Store{
+		backends: map[string]backend{
+			"keyring": &keyringBackend{fallback: file},
+			"file":    file,
+		},
+	}
+}
+
+// Backends returns the names of the backends of the store
+func (s *Store) Backends() []string {
+	names := []string{}
+	for name := range s.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) backend(ref string) (backend, string, error) {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, "", fmt.Errorf("invalid credential reference %q, expected <backend>:<key>", ref)
+	}
+	b, ok := s.backends[parts[0]]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown credential backend %q, expected one of %s", parts[0], strings.Join(s.Backends(), ", "))
+	}
+	return b, parts[1], nil
+}
+
+// Get returns the secret ref references, config.ErrCredentialNotFound when
+// there is none
+func (s *Store) Get(ref string) (string, error) {
+	b, key, err := s.backend(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.Get(key)
+}
+
+// Set stores value as the secret ref references
+func (s *Store) Set(ref, value string) error {
+	b, key, err := s.backend(ref)
+	if err != nil {
+		return err
+	}
+	return b.Set(key, value)
+}
+
+// Delete removes the secret ref references
+func (s *Store) Delete(ref string) error {
+	b, key, err := s.backend(ref)
+	if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
+
+var _ config.CredentialStore = &Store{}
@@ -0,0 +1,95 @@
+package credstore_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/config"
+	"github.com/ibm/vault-cli/pkg/credstore"
+)
+
+func TestFileBackend(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "credstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	asked := 0
+	ask := func(passphrase string) func() (string, error) {
+		return func() (string, error) {
+			asked++
+			return passphrase, nil
+		}
+	}
+	store := credstore.New(dir, ask("correct horse"))
+
+	if _, err := store.Get("file:vaultcli/user/alice/password"); !errors.Is(err, config.ErrCredentialNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if asked != 0 {
+		t.Errorf("expected no passphrase to be asked without file")
+	}
+	if err := store.Set("file:vaultcli/user/alice/password", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("file:vaultcli/context/dev/token", "s.token"); err != nil {
+		t.Fatal(err)
+	}
+	if asked != 1 {
+		t.Errorf("expected the passphrase to be asked once, got %d", asked)
+	}
+
+	content, err := ioutil.ReadFile(dir + "/" + credstore.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "s3cret") {
+		t.Errorf("expected the file to be encrypted")
+	}
+	info, err := os.Stat(dir + "/" + credstore.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected mode %o", info.Mode().Perm())
+	}
+
+	again := credstore.New(dir, ask("correct horse"))
+	if v, err := again.Get("file:vaultcli/user/alice/password"); err != nil || v != "s3cret" {
+		t.Errorf("unexpected secret %q %v", v, err)
+	}
+	if err := again.Delete("file:vaultcli/user/alice/password"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := credstore.New(dir, ask("correct horse")).Get("file:vaultcli/context/dev/token"); err != nil || v != "s.token" {
+		t.Errorf("unexpected secret %q %v", v, err)
+	}
+	if _, err := credstore.New(dir, ask("correct horse")).Get("file:vaultcli/user/alice/password"); !errors.Is(err, config.ErrCredentialNotFound) {
+		t.Errorf("expected deleted secret to be gone, got %v", err)
+	}
+
+	if _, err := credstore.New(dir, ask("wrong")).Get("file:vaultcli/context/dev/token"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected wrong passphrase error, got %v", err)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	t.Parallel()
+
+	store := credstore.New(os.TempDir(), func() (string, error) { return "", nil })
+	for ref, expected := range map[string]string{
+		"vaultcli/alice":       "invalid credential reference",
+		"file:":                "invalid credential reference",
+		"vault:vaultcli/alice": `unknown credential backend "vault", expected one of file, keyring`,
+	} {
+		if _, err := store.Get(ref); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", ref, expected, err)
+		}
+	}
+}
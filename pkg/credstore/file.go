@@ -0,0 +1,173 @@
+package credstore
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// encryptedFile is the content of the encrypted file, data is the json of
+// the secrets sealed with a key derived from the passphrase and salt
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// fileBackend keeps secrets in a passphrase encrypted file. The passphrase
+// is only asked for once the file is read or written.
+type fileBackend struct {
+	path       string
+	passphrase func() (string, error)
+
+	key     *[32]byte
+	salt    []byte
+	secrets map[string]string
+}
+
+func (f *fileBackend) Get(key string) (string, error) {
+	err := f.load(false)
+	if err != nil {
+		return "", err
+	}
+	value, ok := f.secrets[key]
+	if !ok {
+		return "", config.ErrCredentialNotFound
+	}
+	return value, nil
+}
+
+func (f *fileBackend) Set(key, value string) error {
+	err := f.load(true)
+	if err != nil {
+		return err
+	}
+	f.secrets[key] = value
+	return f.save()
+}
+
+func (f *fileBackend) Delete(key string) error {
+	err := f.load(false)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.secrets[key]; !ok {
+		return nil
+	}
+	delete(f.secrets, key)
+	return f.save()
+}
+
+// load reads and decrypts the file, a missing file has no secrets and only
+// needs a passphrase when it is about to be written
+func (f *fileBackend) load(writing bool) error {
+	if f.secrets != nil && (f.key != nil || !writing) {
+		return nil
+	}
+	bytes, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		if f.secrets == nil {
+			f.secrets = map[string]string{}
+		}
+		if !writing {
+			return nil
+		}
+		f.salt = make([]byte, 16)
+		if _, err := rand.Read(f.salt); err != nil {
+			return err
+		}
+		return f.deriveKey()
+	} else if err != nil {
+		return err
+	}
+
+	enc := encryptedFile{}
+	err = json.Unmarshal(bytes, &enc)
+	if err != nil || enc.Version != 1 || len(enc.Nonce) != 24 {
+		return fmt.Errorf("%s is not a vault-cli credential file", f.path)
+	}
+	f.salt = enc.Salt
+	err = f.deriveKey()
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	copy(nonce[:], enc.Nonce)
+	data, ok := secretbox.Open(nil, enc.Data, &nonce, f.key)
+	if !ok {
+		f.key = nil
+		return fmt.Errorf("unable to decrypt %s, wrong passphrase", f.path)
+	}
+	secrets := map[string]string{}
+	err = json.Unmarshal(data, &secrets)
+	if err != nil {
+		return err
+	}
+	f.secrets = secrets
+	return nil
+}
+
+func (f *fileBackend) deriveKey() error {
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return errors.New("the credential file needs a passphrase")
+	}
+	key, err := scrypt.Key([]byte(passphrase), f.salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return err
+	}
+	f.key = &[32]byte{}
+	copy(f.key[:], key)
+	return nil
+}
+
+// save encrypts the secrets with a new nonce and replaces the file
+func (f *fileBackend) save() error {
+	data, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(encryptedFile{
+		Version: 1,
+		Salt:    f.salt,
+		Nonce:   nonce[:],
+		Data:    secretbox.Seal(nil, data, &nonce, f.key),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = tmp.Chmod(0600)
+	if err == nil {
+		_, err = tmp.Write(bytes)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,46 @@
+package credstore
+
+import (
+	"errors"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// keyringService is the service the secrets are stored under in the OS
+// secret service
+const keyringService = "vaultcli"
+
+// errNoKeyring is returned by the keyring functions of the OS when its
+// secret service cannot be reached
+var errNoKeyring = errors.New("no OS keyring")
+
+// keyringBackend keeps secrets in the secret service of the OS, or in the
+// fallback when it cannot be reached
+type keyringBackend struct {
+	fallback backend
+}
+
+func (k *keyringBackend) Get(key string) (string, error) {
+	value, err := keyringGet(key)
+	if errors.Is(err, errNoKeyring) || errors.Is(err, config.ErrCredentialNotFound) {
+		// it may have been stored without keyring
+		return k.fallback.Get(key)
+	}
+	return value, err
+}
+
+func (k *keyringBackend) Set(key, value string) error {
+	err := keyringSet(key, value)
+	if errors.Is(err, errNoKeyring) {
+		return k.fallback.Set(key, value)
+	}
+	return err
+}
+
+func (k *keyringBackend) Delete(key string) error {
+	err := keyringDelete(key)
+	if err != nil && !errors.Is(err, errNoKeyring) {
+		return err
+	}
+	return k.fallback.Delete(key)
+}
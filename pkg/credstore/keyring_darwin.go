@@ -0,0 +1,76 @@
+package credstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// base64Prefix marks values stored base64 encoded, so that any value can
+// be passed to security -i
+const base64Prefix = "vault-cli-base64:"
+
+// security runs the security tool of macOS on the login keychain
+func security(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return "", errNoKeyring
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 44 {
+		// the item could not be found in the keychain
+		return "", config.ErrCredentialNotFound
+	} else if err != nil {
+		if stderr.Len() == 0 {
+			return "", err
+		}
+		return "", fmt.Errorf("security: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// quote quotes s for the command line of security -i
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func keyringGet(key string) (string, error) {
+	value, err := security("", "find-generic-password", "-s", keyringService, "-a", key, "-w")
+	if err != nil {
+		return "", err
+	}
+	value = strings.TrimSuffix(value, "\n")
+	if strings.HasPrefix(value, base64Prefix) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, base64Prefix))
+		if err != nil {
+			return "", err
+		}
+		value = string(decoded)
+	}
+	return value, nil
+}
+
+func keyringSet(key, value string) error {
+	// the value is passed on stdin rather than as an argument other
+	// processes can see
+	encoded := base64Prefix + base64.StdEncoding.EncodeToString([]byte(value))
+	_, err := security(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(keyringService), quote(key), quote(encoded)), "-i")
+	return err
+}
+
+func keyringDelete(key string) error {
+	_, err := security("", "delete-generic-password", "-s", keyringService, "-a", key)
+	if err == config.ErrCredentialNotFound {
+		return nil
+	}
+	return err
+}
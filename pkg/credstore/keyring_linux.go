@@ -0,0 +1,55 @@
+package credstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ibm/vault-cli/pkg/config"
+)
+
+// secretTool runs secret-tool of libsecret, which talks to the secret
+// service over D-Bus
+func secretTool(stdin string, args ...string) (string, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return "", errNoKeyring
+	}
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", errNoKeyring
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		if stderr.Len() == 0 {
+			return "", err
+		}
+		return "", fmt.Errorf("secret-tool %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func keyringGet(key string) (string, error) {
+	value, err := secretTool("", "lookup", "service", keyringService, "account", key)
+	if _, ok := err.(*exec.ExitError); ok {
+		// lookup exits 1 without output when there is no such secret
+		return "", config.ErrCredentialNotFound
+	}
+	return strings.TrimSuffix(value, "\n"), err
+}
+
+func keyringSet(key, value string) error {
+	_, err := secretTool(value, "store", "--label", "vault-cli "+key, "service", keyringService, "account", key)
+	return err
+}
+
+func keyringDelete(key string) error {
+	_, err := secretTool("", "clear", "service", keyringService, "account", key)
+	return err
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package credstore
+
+// keyring functions of the systems without supported secret service, the
+// secrets go to the encrypted file
+
+func keyringGet(key string) (string, error) {
+	return "", errNoKeyring
+}
+
+func keyringSet(key, value string) error {
+	return errNoKeyring
+}
+
+func keyringDelete(key string) error {
+	return errNoKeyring
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Action is what a put did to a vault object
+type Action string
+
+// Actions of an event
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionDeleted   Action = "deleted"
+	ActionFailed    Action = "failed"
+	// ActionApplied is recorded when the object was written but its prior
+	// state could not be read
+	ActionApplied Action = "applied"
+)
+
+// Event is the outcome of putting one inventory file, of one write of a
+// saved plan or of one delete. Deletes have no file. Duration is in seconds.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	File      string    `json:"file"`
+	Namespace string    `json:"namespace,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Action    Action    `json:"action"`
+	Error     string    `json:"error,omitempty"`
+	Duration  float64   `json:"duration"`
+}
+
+// Recorder keeps the events of a run and streams them as newline delimited
+// json
+type Recorder struct {
+	w      io.Writer
+	events []*Event
+}
+
+// NewRecorder returns a recorder writing each event as a json line to w. w
+// may be nil when only the junit report is wanted.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Record keeps e and writes it to the stream
+func (r *Recorder) Record(e *Event) error {
+	r.events = append(r.events, e)
+	if r.w == nil {
+		return nil
+	}
+	out, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %s", err)
+	}
+	_, err = fmt.Fprintf(r.w, "%s\n", out)
+	return err
+}
+
+// Events returns the events recorded so far
+func (r *Recorder) Events() []*Event {
+	return r.events
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the events as a junit xml report named name, with a
+// test suite per kind and a test case per inventory file, or per path for
+// deletes
+func (r *Recorder) WriteJUnit(w io.Writer, name string) error {
+	report := junitTestSuites{Name: name}
+	suites := map[string]int{}
+	durations := []float64{}
+	total := 0.0
+	for _, e := range r.events {
+		i, ok := suites[e.Kind]
+		if !ok {
+			i = len(report.Suites)
+			suites[e.Kind] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: e.Kind, Timestamp: e.Time.UTC().Format(time.RFC3339)})
+			durations = append(durations, 0)
+		}
+		suite := &report.Suites[i]
+		name := e.File
+		if name == "" {
+			name = e.Path
+		}
+		tc := junitTestCase{
+			Name:      name,
+			Classname: e.Kind,
+			Time:      seconds(e.Duration),
+			SystemOut: fmt.Sprintf("%s %s", e.Action, e.Path),
+		}
+		if e.Action == ActionFailed {
+			tc.Failure = &junitFailure{Message: e.Error, Type: string(ActionFailed), Text: e.Error}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		report.Tests++
+		durations[i] += e.Duration
+		total += e.Duration
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = seconds(durations[i])
+	}
+	report.Time = seconds(total)
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling junit report: %s", err)
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, out)
+	return err
+}
+
+func seconds(d float64) string {
+	return fmt.Sprintf("%.3f", d)
+}
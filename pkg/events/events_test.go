@@ -0,0 +1,98 @@
+package events_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm/vault-cli/pkg/events"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	var stream bytes.Buffer
+	r := events.NewRecorder(&stream)
+	at := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	recorded := []*events.Event{
+		{Time: at, Kind: "vaultpolicy", File: "parent-operator", Namespace: "parent", Path: "sys/policy/operator", Action: events.ActionCreated, Duration: 0.25},
+		{Time: at, Kind: "vaultpolicy", File: "parent-admin", Namespace: "parent", Path: "sys/policy/admin", Action: events.ActionFailed, Error: "permission denied", Duration: 0.5},
+		{Time: at, Kind: "vaultrole", File: "parent-myauth-operator", Namespace: "parent", Path: "auth/myauth/role/operator", Action: events.ActionUnchanged, Duration: 1},
+		{Time: at, Kind: "vaultpolicy", Namespace: "parent", Path: "sys/policy/old", Action: events.ActionDeleted},
+	}
+	for _, e := range recorded {
+		if err := r.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stream.String(), "\n"), "\n")
+	if len(lines) != len(recorded) {
+		t.Fatalf("expected %d lines, got %q", len(recorded), stream.String())
+	}
+	expected := `{"time":"2021-03-01T12:00:00Z","kind":"vaultpolicy","file":"parent-admin","namespace":"parent","path":"sys/policy/admin","action":"failed","error":"permission denied","duration":0.5}`
+	if lines[1] != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, lines[1])
+	}
+	for _, line := range lines {
+		e := events.Event{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Errorf("%s: %s", line, err)
+		}
+	}
+	if len(r.Events()) != len(recorded) {
+		t.Errorf("expected %d events, got %d", len(recorded), len(r.Events()))
+	}
+
+	var report bytes.Buffer
+	if err := r.WriteJUnit(&report, "vault-cli ns-test"); err != nil {
+		t.Fatal(err)
+	}
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Time     string `xml:"time,attr"`
+			Cases    []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(report.Bytes(), &suites); err != nil {
+		t.Fatalf("%s\n%s", err, report.String())
+	}
+	if suites.Tests != 4 || suites.Failures != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("unexpected report\n%s", report.String())
+	}
+	policies := suites.Suites[0]
+	if policies.Name != "vaultpolicy" || policies.Tests != 3 || policies.Failures != 1 || policies.Time != "0.750" {
+		t.Errorf("unexpected suite %+v", policies)
+	}
+	if policies.Cases[1].Failure == nil || policies.Cases[1].Failure.Message != "permission denied" {
+		t.Errorf("expected parent-admin to fail, got %+v", policies.Cases[1])
+	}
+	if policies.Cases[2].Name != "sys/policy/old" {
+		t.Errorf("expected deletes to be named by path, got %s", policies.Cases[2].Name)
+	}
+}
+
+func TestRecorderWithoutStream(t *testing.T) {
+	t.Parallel()
+
+	r := events.NewRecorder(nil)
+	if err := r.Record(&events.Event{Kind: "vaultpolicy", File: "parent-operator", Action: events.ActionUpdated}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Events()) != 1 {
+		t.Errorf("expected the event to be kept")
+	}
+}
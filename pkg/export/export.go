@@ -0,0 +1,125 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+)
+
+// APIVersion is the apiVersion of every inventory document
+const APIVersion = "api.gensec.ibm.com/v1"
+
+// Document is an inventory yaml file as read by the put commands
+type Document struct {
+	APIVersion string      `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string      `json:"kind" yaml:"kind"`
+	Metadata   Metadata    `json:"metadata" yaml:"metadata"`
+	Spec       interface{} `json:"spec" yaml:"spec"`
+}
+
+// Metadata holds the name of an inventory document, which is also its file name
+type Metadata struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// NewDocument returns the inventory document of kind for spec
+func NewDocument(kind, name string, spec interface{}) *Document {
+	return &Document{
+		APIVersion: APIVersion,
+		Kind:       kind,
+		Metadata:   Metadata{Name: name},
+		Spec:       spec,
+	}
+}
+
+// Marshal returns the yaml inventory document of kind for spec
+func Marshal(kind, name string, spec interface{}) ([]byte, error) {
+	return yaml.Marshal(NewDocument(kind, name, spec))
+}
+
+// Decode fills the fields of out from data read from vault, matching the
+// vault struct tags put uses to write them. Vault returns ttls as seconds,
+// lists as arrays or comma separated strings and numbers as json.Number, so
+// values are converted to the field types where possible.
+func Decode(data map[string]interface{}, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "vault",
+		WeaklyTypedInput: true,
+		Result:           out,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			jsonNumberHook,
+			durationHook,
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(data)
+}
+
+// jsonNumberHook turns json.Number into the int or float the field wants
+func jsonNumberHook(from, to reflect.Type, v interface{}) (interface{}, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v, nil
+	}
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return n.Float64()
+	}
+	return n.String(), nil
+}
+
+// durationHook turns duration strings such as "30m" into seconds for
+// integer fields
+func durationHook(from, to reflect.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if d, err := time.ParseDuration(s); err == nil {
+			return int64(d.Seconds()), nil
+		}
+	}
+	return v, nil
+}
+
+// ParsePolicy parses the hcl rules of a vault policy back into the paths
+// and capabilities of a VaultPolicy
+func ParsePolicy(rules string) (vaultapi.HCLPolicies, error) {
+	policies := vaultapi.HCLPolicies{}
+	err := hcl.Decode(&policies, rules)
+	if err != nil {
+		return policies, fmt.Errorf("unable to parse policy: %s", err)
+	}
+	return policies, nil
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// FileName joins the parts of an object name into an inventory file name
+func FileName(parts ...string) string {
+	names := []string{}
+	for _, p := range parts {
+		p = strings.Trim(unsafeNameChars.ReplaceAllString(p, "-"), "-")
+		if p != "" {
+			names = append(names, p)
+		}
+	}
+	return strings.Join(names, "-")
+}
@@ -0,0 +1,121 @@
+package export_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/export"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]interface{}{
+		"allowed_users":           "*",
+		"default_extensions":      map[string]interface{}{"permit-pty": ""},
+		"ttl":                     json.Number("1800"),
+		"max_ttl":                 "30m",
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+	}
+	params := vaultapi.SSHRoleParameters{}
+	if err := export.Decode(data, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.AllowedUsers != "*" || params.KeyType != "ca" || !params.AllowUserCertificates {
+		t.Errorf("unexpected parameters %+v", params)
+	}
+	if params.TTL != "1800" || params.MaxTTL != "30m" {
+		t.Errorf("unexpected ttls %q %q", params.TTL, params.MaxTTL)
+	}
+
+	role := vaultapi.VaultRoleData{}
+	err := export.Decode(map[string]interface{}{
+		"token_policies": []interface{}{"operator"},
+		"policies":       "operator,admin",
+		"token_ttl":      json.Number("4500"),
+		"token_max_ttl":  "1h",
+		"bind_secret_id": true,
+	}, &role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(role.TokenPolicies, []string{"operator"}) || !reflect.DeepEqual(role.Policies, []string{"operator", "admin"}) {
+		t.Errorf("unexpected policies %v %v", role.TokenPolicies, role.Policies)
+	}
+	if role.TokenTTL != 4500 || role.TokenMaxTTL != 3600 || !role.BindSecretID {
+		t.Errorf("unexpected role %+v", role)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	rules := `
+path "secret/*" {
+  capabilities = ["create", "read", "list"]
+}
+
+path "pki/issue/tls" {
+  capabilities = ["update"]
+}
+`
+	policies, err := export.ParsePolicy(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := vaultapi.HCLPolicies{Paths: []vaultapi.PolicyPath{
+		{Name: "secret/*", Capabilities: []string{"create", "read", "list"}},
+		{Name: "pki/issue/tls", Capabilities: []string{"update"}},
+	}}
+	if !reflect.DeepEqual(policies, expected) {
+		t.Errorf("expected %+v got %+v", expected, policies)
+	}
+
+	if _, err := export.ParsePolicy(`path "secret/*" {`); err == nil {
+		t.Errorf("expected parse error")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	spec := vaultapi.VaultPolicySpec{
+		VaultNamespace: "parent",
+		PolicyName:     "operator",
+		Policies: vaultapi.HCLPolicies{Paths: []vaultapi.PolicyPath{
+			{Name: "secret/*", Capabilities: []string{"read"}},
+		}},
+	}
+	out, err := export.Marshal("VaultPolicy", "parent-operator", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), "apiVersion: api.gensec.ibm.com/v1\nkind: VaultPolicy\nmetadata:\n  name: parent-operator\n") {
+		t.Errorf("unexpected document\n%s", out)
+	}
+
+	// the document reads back the way put reads inventory files
+	policy := vaultapi.VaultPolicy{}
+	if err := yaml.Unmarshal(out, &policy); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy.Spec, spec) {
+		t.Errorf("expected %+v got %+v", spec, policy.Spec)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+
+	if name := export.FileName("parent/child", "pki/int", "server cert"); name != "parent-child-pki-int-server-cert" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if name := export.FileName("root", "", "tls"); name != "root-tls" {
+		t.Errorf("unexpected name %s", name)
+	}
+}
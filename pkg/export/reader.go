@@ -0,0 +1,383 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pkgargs "github.com/ibm/vault-cli/pkg/args"
+	"github.com/ibm/vault-cli/pkg/owner"
+	"github.com/ibm/vault-cli/pkg/secretservice"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+)
+
+// Reader reads live vault objects back into the vault-go api/v1 specs the
+// put commands write them from. Namespaces are given the way vault takes
+// them, "" for the root namespace.
+type Reader struct {
+	svc secretservice.SecretService
+
+	// Warn is called with the fields of an object that could not be
+	// converted to its spec. The rest of the spec is still returned.
+	Warn func(name string, err error)
+}
+
+// NewReader returns a Reader that reads through svc
+func NewReader(svc secretservice.SecretService) *Reader {
+	return &Reader{svc: svc, Warn: func(string, error) {}}
+}
+
+// Namespace is the namespace as written in inventory files, "root" for the
+// root namespace
+func Namespace(ns string) string {
+	ns = strings.Trim(ns, "/")
+	if ns == "" {
+		return "root"
+	}
+	return ns
+}
+
+// Namespaces lists the child namespaces of ns. Vault without namespaces has
+// none.
+func (r *Reader) Namespaces(ns string) ([]string, error) {
+	return r.list(ns, "sys/namespaces")
+}
+
+// Namespace reads the child namespace name of ns
+func (r *Reader) Namespace(ns, name string) (*vaultapi.VaultNamespaceSpec, error) {
+	data, err := r.read(ns, "sys/namespaces/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, notFound(ns, "sys/namespaces/"+name)
+	}
+	return &vaultapi.VaultNamespaceSpec{NamespaceBase: Namespace(ns), NamespaceName: name}, nil
+}
+
+// Endpoints lists the paths of the secret engines mounted in ns
+func (r *Reader) Endpoints(ns string) ([]string, error) {
+	return r.mountPaths(ns, "sys/mounts")
+}
+
+// Endpoint reads the secret engine mounted at path in ns with its tuning.
+// PKI CA configuration is not read back. Descriptions keep their ownership
+// marker, see StripMarkers.
+func (r *Reader) Endpoint(ns, path string) (*vaultapi.VaultEndpointSpec, error) {
+	path = strings.Trim(path, "/")
+	mount, err := r.mount(ns, "sys/mounts", path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.VaultEndpointSpec{VaultNamespace: Namespace(ns), Path: path}
+	r.decode(path, mount, &spec.MountOptions)
+	if options, ok := mount["options"].(map[string]interface{}); ok && spec.MountOptions.Type == "kv" && fmt.Sprint(options["version"]) == "2" {
+		spec.MountOptions.Type = "kv-v2"
+	}
+	tune, err := r.read(ns, fmt.Sprintf("sys/mounts/%s/tune", path))
+	if err != nil {
+		return nil, err
+	}
+	if tune != nil {
+		r.decode(path, tune, &spec.TuneOptions)
+	}
+	return spec, nil
+}
+
+// EndpointType returns the type of the secret engine mounted at path in ns,
+// kv-v2 for version 2 kv engines
+func (r *Reader) EndpointType(ns, path string) (string, error) {
+	mount, err := r.mount(ns, "sys/mounts", strings.Trim(path, "/"))
+	if err != nil {
+		return "", err
+	}
+	t, _ := mount["type"].(string)
+	if options, ok := mount["options"].(map[string]interface{}); ok && t == "kv" && fmt.Sprint(options["version"]) == "2" {
+		t = "kv-v2"
+	}
+	return t, nil
+}
+
+// Auths lists the paths of the auth methods enabled in ns
+func (r *Reader) Auths(ns string) ([]string, error) {
+	return r.mountPaths(ns, "sys/auth")
+}
+
+// Auth reads the auth method enabled at path in ns, with its jwt
+// configuration for jwt and oidc methods. The description keeps its
+// ownership marker, see StripMarkers.
+func (r *Reader) Auth(ns, path string) (*vaultapi.VaultAuthSpec, error) {
+	path = strings.Trim(path, "/")
+	auth, err := r.mount(ns, "sys/auth", path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.VaultAuthSpec{VaultNamespace: Namespace(ns), Path: path}
+	r.decode(path, auth, &spec.Data)
+	if IsJWT(spec.Data.Type) {
+		config, err := r.read(ns, fmt.Sprintf("auth/%s/config", path))
+		if err != nil {
+			return nil, err
+		}
+		if config != nil {
+			r.decode(path, config, &spec.JWTConfig)
+		}
+	}
+	return spec, nil
+}
+
+// AuthType returns the type of the auth method enabled at path in ns
+func (r *Reader) AuthType(ns, path string) (string, error) {
+	auth, err := r.mount(ns, "sys/auth", strings.Trim(path, "/"))
+	if err != nil {
+		return "", err
+	}
+	t, _ := auth["type"].(string)
+	return t, nil
+}
+
+// StripMarkers removes the vault-cli ownership marker from the descriptions
+// of an endpoint or auth spec, so the spec reads like the inventory file it
+// was put from
+func StripMarkers(spec interface{}) {
+	switch s := spec.(type) {
+	case *vaultapi.VaultEndpointSpec:
+		s.MountOptions.Description = owner.Strip(s.MountOptions.Description)
+		s.TuneOptions.Description = owner.Strip(s.TuneOptions.Description)
+	case *vaultapi.VaultAuthSpec:
+		s.Data.Description = owner.Strip(s.Data.Description)
+	}
+}
+
+// IsJWT reports whether roles of the auth method type are JWTRoles rather
+// than VaultRoles
+func IsJWT(authType string) bool {
+	return authType == "jwt" || authType == "oidc"
+}
+
+// Policies lists the acl policies of ns
+func (r *Reader) Policies(ns string) ([]string, error) {
+	return r.list(ns, "sys/policy")
+}
+
+// Policy reads the acl policy name of ns, parsing its rules into paths
+func (r *Reader) Policy(ns, name string) (*vaultapi.VaultPolicySpec, error) {
+	data, err := r.read(ns, "sys/policy/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, notFound(ns, "sys/policy/"+name)
+	}
+	rules, _ := data["rules"].(string)
+	policies, err := ParsePolicy(rules)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultapi.VaultPolicySpec{VaultNamespace: Namespace(ns), PolicyName: name, Policies: policies}, nil
+}
+
+// AuthRoles lists the roles of the auth method at authPath in ns
+func (r *Reader) AuthRoles(ns, authPath string) ([]string, error) {
+	return r.list(ns, fmt.Sprintf("auth/%s/role", strings.Trim(authPath, "/")))
+}
+
+// VaultRole reads the role name of the auth method at authPath in ns
+func (r *Reader) VaultRole(ns, authPath, name string) (*vaultapi.VaultRoleSpec, error) {
+	authPath = strings.Trim(authPath, "/")
+	data, err := r.role(ns, fmt.Sprintf("auth/%s/role/%s", authPath, name))
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.VaultRoleSpec{AuthMethod: authPath, RoleName: name, VaultNamespace: Namespace(ns)}
+	r.decode(name, data, &spec.Data)
+	return spec, nil
+}
+
+// JWTRole reads the role name of the jwt auth method at authPath in ns
+func (r *Reader) JWTRole(ns, authPath, name string) (*vaultapi.JWTRoleSpec, error) {
+	authPath = strings.Trim(authPath, "/")
+	data, err := r.role(ns, fmt.Sprintf("auth/%s/role/%s", authPath, name))
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.JWTRoleSpec{AuthPath: authPath, RoleName: name, VaultNamespace: Namespace(ns)}
+	r.decode(name, data, &spec.Parameters)
+	return spec, nil
+}
+
+// EngineRoles lists the roles of the pki or ssh secret engine at path in ns
+func (r *Reader) EngineRoles(ns, path string) ([]string, error) {
+	return r.list(ns, strings.Trim(path, "/")+"/roles")
+}
+
+// PKIRole reads the role name of the pki secret engine at path in ns
+func (r *Reader) PKIRole(ns, path, name string) (*vaultapi.PKIRoleSpec, error) {
+	path = strings.Trim(path, "/")
+	data, err := r.role(ns, fmt.Sprintf("%s/roles/%s", path, name))
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.PKIRoleSpec{IssuerPath: path, RoleName: name, VaultNamespace: Namespace(ns)}
+	r.decode(name, data, &spec.Config)
+	return spec, nil
+}
+
+// SSHRole reads the role name of the ssh secret engine at path in ns
+func (r *Reader) SSHRole(ns, path, name string) (*vaultapi.SSHRoleSpec, error) {
+	path = strings.Trim(path, "/")
+	data, err := r.role(ns, fmt.Sprintf("%s/roles/%s", path, name))
+	if err != nil {
+		return nil, err
+	}
+	spec := &vaultapi.SSHRoleSpec{SignerPath: path, RoleName: name, VaultNamespace: Namespace(ns)}
+	r.decode(name, data, &spec.Parameters)
+	return spec, nil
+}
+
+// Secret reads the kv secret at path in ns. Only the key names are returned,
+// never the values, with the custom metadata of kv-v2 secrets.
+func (r *Reader) Secret(ns, path string) (*vaultapi.SecretMetaSpec, map[string]string, error) {
+	path = strings.Trim(path, "/")
+	var spec *vaultapi.SecretMetaSpec
+	metadata := map[string]string{}
+	err := r.in(ns, func() error {
+		mountPath, v2, err := r.svc.IsKVv2(path)
+		if err != nil {
+			return err
+		}
+		apiPath := path
+		if v2 {
+			apiPath = pkgargs.AddPrefixToVKVPath(path, mountPath, "data")
+		}
+		secret, err := r.svc.Read(apiPath)
+		if err != nil {
+			return err
+		}
+		if secret == nil || secret.Data == nil {
+			return notFound(ns, path)
+		}
+		data := secret.Data
+		if v2 {
+			data, _ = secret.Data["data"].(map[string]interface{})
+			if m, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+				custom, _ := m["custom_metadata"].(map[string]interface{})
+				for k, v := range custom {
+					metadata[k] = fmt.Sprint(v)
+				}
+			}
+		}
+		spec = &vaultapi.SecretMetaSpec{KVPath: vaultapi.KVPath{Path: path}}
+		if v2 {
+			spec.Type = string(vaultapi.SecretTypeKVV2)
+		}
+		for _, k := range sortedKeys(data) {
+			spec.KVPath.Keys = append(spec.KVPath.Keys, vaultapi.KVKey{Name: k})
+		}
+		return nil
+	})
+	return spec, metadata, err
+}
+
+// role reads the role at path in ns
+func (r *Reader) role(ns, path string) (map[string]interface{}, error) {
+	data, err := r.read(ns, path)
+	if err == nil && data == nil {
+		err = notFound(ns, path)
+	}
+	return data, err
+}
+
+// mount returns the mount at path from the sys/mounts or sys/auth listing
+// of ns
+func (r *Reader) mount(ns, listing, path string) (map[string]interface{}, error) {
+	mounts, err := r.read(ns, listing)
+	if err != nil {
+		return nil, err
+	}
+	mount, ok := mounts[path+"/"].(map[string]interface{})
+	if !ok {
+		return nil, notFound(ns, listing+"/"+path)
+	}
+	return mount, nil
+}
+
+// mountPaths lists the mounts of the sys/mounts or sys/auth listing of ns
+func (r *Reader) mountPaths(ns, listing string) ([]string, error) {
+	mounts, err := r.read(ns, listing)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, p := range sortedKeys(mounts) {
+		if _, ok := mounts[p].(map[string]interface{}); ok {
+			paths = append(paths, strings.TrimSuffix(p, "/"))
+		}
+	}
+	return paths, nil
+}
+
+// read returns the data at path in ns, nil if nothing is there
+func (r *Reader) read(ns, path string) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	err := r.in(ns, func() error {
+		secret, err := r.svc.Read(path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s (namespace: %s) %s", path, Namespace(ns), err)
+		}
+		if secret != nil {
+			data = secret.Data
+		}
+		return nil
+	})
+	return data, err
+}
+
+// list returns the keys listed at path in ns, none when nothing is there
+func (r *Reader) list(ns, path string) ([]string, error) {
+	keys := []string{}
+	err := r.in(ns, func() error {
+		secret, err := r.svc.List(path)
+		if err != nil {
+			return fmt.Errorf("unable to list %s (namespace: %s) %s", path, Namespace(ns), err)
+		}
+		if secret == nil {
+			return nil
+		}
+		list, _ := secret.Data["keys"].([]interface{})
+		for _, k := range list {
+			keys = append(keys, strings.TrimSuffix(fmt.Sprint(k), "/"))
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// in runs fn with the client in namespace ns, restoring the namespace after
+func (r *Reader) in(ns string, fn func() error) error {
+	client := r.svc.GetClient()
+	previous := client.Headers().Get("X-Vault-Namespace")
+	client.SetNamespace(strings.Trim(ns, "/"))
+	defer client.SetNamespace(previous)
+	return fn()
+}
+
+func (r *Reader) decode(name string, data map[string]interface{}, out interface{}) {
+	if err := Decode(data, out); err != nil {
+		r.Warn(name, err)
+	}
+}
+
+func notFound(ns, path string) error {
+	return fmt.Errorf("%s not found (namespace: %s)", path, Namespace(ns))
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package export_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/export"
+	"github.com/ibm/vault-cli/pkg/secretservice/fakes"
+	vaultapi "github.com/ibm/vault-go/api/v1"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetNamespace("team")
+	namespaces := []string{}
+	data := map[string]map[string]interface{}{
+		"sys/mounts": {
+			"sys/": map[string]interface{}{"type": "system"},
+			"secret/": map[string]interface{}{
+				"type":        "kv",
+				"description": "team secrets [vault-cli context=ns-test file=vaultendpoint/secret]",
+				"options":     map[string]interface{}{"version": "2"},
+			},
+		},
+		"sys/mounts/secret/tune": {"max_lease_ttl": "1h"},
+		"sys/policy/operator":    {"rules": `path "secret/*" { capabilities = ["read"] }`},
+		"secret/data/app":        {"data": map[string]interface{}{"user": "u", "password": "p"}, "metadata": map[string]interface{}{"custom_metadata": map[string]interface{}{"vault-cli-context": "ns-test"}}},
+	}
+	svc := &fakes.FakeSecretService{}
+	svc.GetClientReturns(client)
+	svc.IsKVv2Returns("secret/", true, nil)
+	svc.ReadStub = func(path string) (*api.Secret, error) {
+		namespaces = append(namespaces, client.Headers().Get("X-Vault-Namespace"))
+		if d, ok := data[path]; ok {
+			return &api.Secret{Data: d}, nil
+		}
+		return nil, nil
+	}
+	svc.ListReturns(&api.Secret{Data: map[string]interface{}{"keys": []interface{}{"b/", "a/"}}}, nil)
+	r := export.NewReader(svc)
+
+	paths, err := r.Endpoints("parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{"secret", "sys"}) {
+		t.Errorf("unexpected endpoints %v", paths)
+	}
+	endpoint, err := r.Endpoint("parent", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint.MountOptions.Type != "kv-v2" || endpoint.VaultNamespace != "parent" || endpoint.TuneOptions.MaxLeaseTTL != 3600 {
+		t.Errorf("unexpected endpoint %+v", endpoint)
+	}
+	export.StripMarkers(endpoint)
+	if endpoint.MountOptions.Description != "team secrets" {
+		t.Errorf("expected marker to be stripped, got %q", endpoint.MountOptions.Description)
+	}
+	if _, err := r.Endpoint("", "pki"); err == nil {
+		t.Errorf("expected pki not to be found")
+	}
+
+	policy, err := r.Policy("", "operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &vaultapi.VaultPolicySpec{
+		VaultNamespace: "root",
+		PolicyName:     "operator",
+		Policies:       vaultapi.HCLPolicies{Paths: []vaultapi.PolicyPath{{Name: "secret/*", Capabilities: []string{"read"}}}},
+	}
+	if !reflect.DeepEqual(policy, expected) {
+		t.Errorf("expected %+v got %+v", expected, policy)
+	}
+
+	// secret values are never read back
+	secret, metadata, err := r.Secret("", "secret/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(secret.KVPath.Keys, []vaultapi.KVKey{{Name: "password"}, {Name: "user"}}) || secret.Type != "kv-v2" {
+		t.Errorf("unexpected secret %+v", secret)
+	}
+	if metadata["vault-cli-context"] != "ns-test" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+
+	roles, err := r.EngineRoles("parent", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roles, []string{"a", "b"}) || svc.ListArgsForCall(0) != "pki/roles" {
+		t.Errorf("unexpected roles %v", roles)
+	}
+
+	if namespaces[0] != "parent" || namespaces[len(namespaces)-1] != "" {
+		t.Errorf("unexpected namespaces %v", namespaces)
+	}
+	if client.Headers().Get("X-Vault-Namespace") != "team" {
+		t.Errorf("expected namespace to be restored")
+	}
+}
@@ -0,0 +1,102 @@
+package fanout
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultParallelism is how many contexts run at once by default
+const DefaultParallelism = 4
+
+// IsList reports whether spec names several contexts: a comma separated
+// list or a glob like prod-*
+func IsList(spec string) bool {
+	return strings.ContainsAny(spec, ",*?[")
+}
+
+// Match returns the names matching spec, a comma separated list of names
+// and globs, in the order of names. Every part of spec must match.
+func Match(spec string, names []string) ([]string, error) {
+	matched := map[string]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		found := false
+		for _, name := range names {
+			ok, err := path.Match(part, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid context pattern %s: %s", part, err)
+			}
+			if ok {
+				matched[name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no context matches %s, available contexts: %s", part, strings.Join(names, ", "))
+		}
+	}
+	contexts := []string{}
+	for _, name := range names {
+		if matched[name] {
+			contexts = append(contexts, name)
+		}
+	}
+	return contexts, nil
+}
+
+// Result is the outcome of running a command in one context
+type Result struct {
+	Context  string
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+}
+
+// Run runs fn for every context, at most parallelism at once, and returns
+// the results in the order of contexts
+func Run(contexts []string, parallelism int, fn func(context string) Result) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	results := make([]Result, len(contexts))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(contexts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				start := time.Now()
+				result := fn(contexts[i])
+				result.Context = contexts[i]
+				result.Duration = time.Since(start)
+				results[i] = result
+			}
+		}()
+	}
+	for i := range contexts {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+// ExitCode returns the highest exit code of results, zero when every
+// context succeeded
+func ExitCode(results []Result) int {
+	code := 0
+	for _, result := range results {
+		if result.ExitCode > code {
+			code = result.ExitCode
+		}
+	}
+	return code
+}
@@ -0,0 +1,82 @@
+package fanout_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/fanout"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"dev", "prod-eu", "prod-us", "staging"}
+	for _, tc := range []struct {
+		spec     string
+		expected []string
+	}{
+		{"prod-*", []string{"prod-eu", "prod-us"}},
+		{"staging,prod-eu", []string{"prod-eu", "staging"}},
+		{"*", names},
+		{"prod-eu, prod-*", []string{"prod-eu", "prod-us"}},
+	} {
+		contexts, err := fanout.Match(tc.spec, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(contexts, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.spec, tc.expected, contexts)
+		}
+	}
+	for _, spec := range []string{"qa-*", "dev,qa", "["} {
+		if _, err := fanout.Match(spec, names); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+	if fanout.IsList("prod-eu") || !fanout.IsList("prod-*") || !fanout.IsList("dev,prod-eu") {
+		t.Errorf("unexpected IsList")
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	contexts := []string{"a", "b", "c", "d", "e"}
+	var mu sync.Mutex
+	running, most := 0, 0
+	release := make(chan struct{})
+	go func() {
+		for range contexts {
+			release <- struct{}{}
+		}
+	}()
+	results := fanout.Run(contexts, 2, func(context string) fanout.Result {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		code := 0
+		if context == "c" {
+			code = 2
+		}
+		return fanout.Result{Stdout: []byte(context), ExitCode: code}
+	})
+	if most > 2 {
+		t.Errorf("expected at most 2 contexts at once, got %d", most)
+	}
+	for i, result := range results {
+		if result.Context != contexts[i] || string(result.Stdout) != contexts[i] {
+			t.Errorf("unexpected result %d %+v", i, result)
+		}
+	}
+	if code := fanout.ExitCode(results); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
@@ -0,0 +1,208 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is the subset of kubectl's jsonpath the -output flag supports:
+// text with {expression} sections, where an expression selects fields
+// (.name or ['name']), array elements ([n]) and all elements or values
+// ([*] or .*) of the json document. The values an expression selects are
+// written separated by spaces.
+type jsonPath struct {
+	// parts are literal text and the steps of expressions, in order
+	parts []jsonPathPart
+}
+
+type jsonPathPart struct {
+	text  string
+	steps []jsonPathStep
+}
+
+// jsonPathStep selects the field name, the element index or, for all,
+// every element or value
+type jsonPathStep struct {
+	name  string
+	index int
+	field bool
+	all   bool
+}
+
+// parseJSONPath parses the text and {expression} sections of expr
+func parseJSONPath(expr string) (*jsonPath, error) {
+	jp := &jsonPath{}
+	for expr != "" {
+		open := strings.Index(expr, "{")
+		if open < 0 {
+			jp.parts = append(jp.parts, jsonPathPart{text: expr})
+			break
+		}
+		if open > 0 {
+			jp.parts = append(jp.parts, jsonPathPart{text: expr[:open]})
+		}
+		end := strings.Index(expr[open:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed expression in %s", expr)
+		}
+		steps, err := parseSteps(expr[open+1 : open+end])
+		if err != nil {
+			return nil, err
+		}
+		jp.parts = append(jp.parts, jsonPathPart{steps: steps})
+		expr = expr[open+end+1:]
+	}
+	return jp, nil
+}
+
+// parseSteps parses an expression such as .resources[*].file
+func parseSteps(expr string) ([]jsonPathStep, error) {
+	steps := []jsonPathStep{}
+	expr = strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			name := expr[:end]
+			expr = expr[end:]
+			switch name {
+			case "":
+				// a lone . selects the document itself
+			case "*":
+				steps = append(steps, jsonPathStep{all: true})
+			default:
+				steps = append(steps, jsonPathStep{name: name, field: true})
+			}
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %s", expr)
+			}
+			key := strings.TrimSpace(expr[1:end])
+			expr = expr[end+1:]
+			switch {
+			case key == "*":
+				steps = append(steps, jsonPathStep{all: true})
+			case len(key) >= 2 && (key[0] == '\'' || key[0] == '"') && key[len(key)-1] == key[0]:
+				steps = append(steps, jsonPathStep{name: key[1 : len(key)-1], field: true})
+			default:
+				index, err := strconv.Atoi(key)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s]", key)
+				}
+				steps = append(steps, jsonPathStep{index: index})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in %s", expr[0], expr)
+		}
+	}
+	return steps, nil
+}
+
+// Execute writes the text and the values the expressions select from data
+func (jp *jsonPath) Execute(w io.Writer, data interface{}) error {
+	for _, part := range jp.parts {
+		if part.steps == nil {
+			if _, err := io.WriteString(w, part.text); err != nil {
+				return err
+			}
+			continue
+		}
+		values := []interface{}{data}
+		for _, step := range part.steps {
+			var err error
+			values, err = step.apply(values)
+			if err != nil {
+				return err
+			}
+		}
+		texts := []string{}
+		for _, v := range values {
+			text, err := jsonPathText(v)
+			if err != nil {
+				return err
+			}
+			texts = append(texts, text)
+		}
+		if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply returns the values step selects from each of values
+func (s jsonPathStep) apply(values []interface{}) ([]interface{}, error) {
+	selected := []interface{}{}
+	for _, v := range values {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if s.all {
+				keys := []string{}
+				for k := range t {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					selected = append(selected, t[k])
+				}
+				continue
+			}
+			if !s.field {
+				return nil, fmt.Errorf("[%d] is not an array", s.index)
+			}
+			e, ok := t[s.name]
+			if !ok {
+				return nil, fmt.Errorf("%s is not found", s.name)
+			}
+			selected = append(selected, e)
+		case []interface{}:
+			if s.all {
+				selected = append(selected, t...)
+				continue
+			}
+			if s.field {
+				return nil, fmt.Errorf("%s is not found in an array", s.name)
+			}
+			index := s.index
+			if index < 0 {
+				index += len(t)
+			}
+			if index < 0 || index >= len(t) {
+				return nil, fmt.Errorf("index [%d] out of range", s.index)
+			}
+			selected = append(selected, t[index])
+		default:
+			if s.field {
+				return nil, fmt.Errorf("%s is not found", s.name)
+			}
+			return nil, fmt.Errorf("%v is not an array or object", v)
+		}
+	}
+	return selected, nil
+}
+
+// jsonPathText returns scalars as their text and objects and arrays as json
+func jsonPathText(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case map[string]interface{}, []interface{}:
+		out, err := json.Marshal(t)
+		if err != nil {
+			return "", fmt.Errorf("error marshaling output: %s", err)
+		}
+		return string(out), nil
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of the -output flag
+const (
+	Text     = "text"
+	JSON     = "json"
+	YAML     = "yaml"
+	JSONPath = "jsonpath"
+	Template = "template"
+)
+
+// Printer prints command results in the format given with -output
+type Printer struct {
+	format string
+	jp     *jsonPath
+	tmpl   *template.Template
+}
+
+// New returns the printer for an -output value: text (the default), json,
+// yaml, jsonpath=<expression> or template=<go template>. go-template is
+// accepted for template. Expressions see the result as its json output.
+func New(output string) (*Printer, error) {
+	format, expr := output, ""
+	if i := strings.Index(output, "="); i >= 0 {
+		format, expr = output[:i], output[i+1:]
+	}
+	p := &Printer{format: format}
+	if (format == JSONPath || format == Template || format == "go-template") && expr == "" {
+		return nil, fmt.Errorf("output format %s needs an expression, e.g. %s=<expression>", format, format)
+	}
+	switch format {
+	case "", Text:
+		p.format = Text
+	case JSON, YAML:
+	case JSONPath:
+		if !strings.Contains(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		jp, err := parseJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %s: %s", expr, err)
+		}
+		p.jp = jp
+	case Template, "go-template":
+		p.format = Template
+		tmpl, err := template.New("output").Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %s: %s", expr, err)
+		}
+		p.tmpl = tmpl
+	default:
+		return nil, fmt.Errorf("unknown output format %s, expected text, json, yaml, jsonpath=<expression> or template=<template>", output)
+	}
+	return p, nil
+}
+
+// Structured reports whether the printer writes a document for scripts
+// rather than text for people
+func (p *Printer) Structured() bool {
+	return p.format != Text
+}
+
+// Print writes v to w. Text output is written by text, which may be nil
+// when v has no text form of its own.
+func (p *Printer) Print(w io.Writer, v interface{}, text func(w io.Writer) error) error {
+	switch p.format {
+	case Text:
+		if text != nil {
+			return text(w)
+		}
+		_, err := fmt.Fprintln(w, v)
+		return err
+	case JSON:
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling output: %s", err)
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	}
+
+	data, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	switch p.format {
+	case YAML:
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error marshaling output: %s", err)
+		}
+		buf.Write(out)
+	case JSONPath:
+		if err := p.jp.Execute(&buf, data); err != nil {
+			return fmt.Errorf("unable to apply jsonpath: %s", err)
+		}
+	case Template:
+		if err := p.tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("unable to apply template: %s", err)
+		}
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// normalize returns v as its json output decodes, so that yaml, jsonpath
+// and templates see the field names of the json output
+func normalize(v interface{}) (interface{}, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling output: %s", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("error marshaling output: %s", err)
+	}
+	return numbers(data), nil
+}
+
+// numbers replaces json.Number with int64 or float64 so yaml writes them as
+// numbers rather than strings
+func numbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = numbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = numbers(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// Table writes rows as columns aligned under header
+func Table(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,85 @@
+package output_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/output"
+)
+
+type result struct {
+	Kind   string            `json:"kind"`
+	File   string            `json:"file"`
+	TTL    int               `json:"ttl"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func TestPrint(t *testing.T) {
+	t.Parallel()
+
+	v := []result{
+		{Kind: "vaultpolicy", File: "parent-operator", TTL: 4500},
+		{Kind: "vaultrole", File: "parent-myauth-operator", TTL: 60, Labels: map[string]string{"team": "a"}},
+	}
+	text := func(w io.Writer) error {
+		rows := [][]string{}
+		for _, r := range v {
+			rows = append(rows, []string{r.Kind, r.File})
+		}
+		return output.Table(w, []string{"KIND", "FILE"}, rows)
+	}
+	tests := map[string]string{
+		"":     "KIND         FILE\nvaultpolicy  parent-operator\nvaultrole    parent-myauth-operator\n",
+		"text": "KIND         FILE\nvaultpolicy  parent-operator\nvaultrole    parent-myauth-operator\n",
+		"json": `[
+  {
+    "kind": "vaultpolicy",
+    "file": "parent-operator",
+    "ttl": 4500
+  },
+  {
+    "kind": "vaultrole",
+    "file": "parent-myauth-operator",
+    "ttl": 60,
+    "labels": {
+      "team": "a"
+    }
+  }
+]
+`,
+		"yaml":                       "- file: parent-operator\n  kind: vaultpolicy\n  ttl: 4500\n- file: parent-myauth-operator\n  kind: vaultrole\n  labels:\n    team: a\n  ttl: 60\n",
+		"jsonpath={[*].file}":        "parent-operator parent-myauth-operator\n",
+		"jsonpath=[1].labels.team":   "a\n",
+		`jsonpath=ttl {[-1]['ttl']}`: "ttl 60\n",
+		"jsonpath={[1].labels}":      "{\"team\":\"a\"}\n",
+		`template={{range .}}{{.kind}}/{{.file}} {{.ttl}}{{"\n"}}{{end}}`: "vaultpolicy/parent-operator 4500\nvaultrole/parent-myauth-operator 60\n",
+		`go-template={{len .}}`: "2\n",
+	}
+	for format, expected := range tests {
+		p, err := output.New(format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		var buf bytes.Buffer
+		if err := p.Print(&buf, v, text); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", format, expected, buf.String())
+		}
+		if p.Structured() != (format != "" && format != "text") {
+			t.Errorf("%s: unexpected structured %v", format, p.Structured())
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"xml", "jsonpath", "template=", "jsonpath={.a", "jsonpath={[a]}", "template={{.a"} {
+		if _, err := output.New(format); err == nil {
+			t.Errorf("%s: expected error", format)
+		}
+	}
+}
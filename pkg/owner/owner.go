@@ -0,0 +1,253 @@
+package owner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	pkgargs "github.com/ibm/vault-cli/pkg/args"
+	"github.com/ibm/vault-cli/pkg/secretservice"
+)
+
+// Record says which inventory file of which context last wrote a vault object
+type Record struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Path      string    `json:"path"`
+	File      string    `json:"file"`
+	Context   string    `json:"context"`
+	Applied   time.Time `json:"applied"`
+}
+
+// Key identifies a vault object by namespace and path. The root namespace
+// may be given as "" or "root".
+func Key(namespace, path string) string {
+	namespace = strings.Trim(namespace, "/")
+	if namespace == "root" {
+		namespace = ""
+	}
+	return namespace + "|" + strings.Trim(path, "/")
+}
+
+// objectPaths match the vault paths of the objects vault-cli puts, tune
+// writes count as writes of the mount they tune
+var objectPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^(sys/(?:mounts|auth)/.+?)(?:/tune)?$`),
+	regexp.MustCompile(`^(sys/(?:policy|namespaces)/[^/]+)$`),
+	regexp.MustCompile(`^(auth/.+/role/[^/]+)$`),
+	regexp.MustCompile(`^(.+/roles/[^/]+)$`),
+}
+
+// ObjectPath returns the path of the object a write to path creates or
+// updates. It returns false for configuration writes such as auth/jwt/config
+// or pki/root/generate/internal, which have no owner of their own.
+func ObjectPath(path string) (string, bool) {
+	path = strings.Trim(path, "/")
+	for _, re := range objectPaths {
+		if m := re.FindStringSubmatch(path); m != nil {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// Owns reports whether r was written by the named context
+func (r *Record) Owns(context string) bool {
+	return r != nil && r.Context == context
+}
+
+// markerRegexp matches the marker Mark appends to a mount or auth description
+var markerRegexp = regexp.MustCompile(`\s*\[vault-cli context=(\S+) file=(\S+)\]$`)
+
+// Mark appends the ownership marker for the context and inventory file to a
+// mount or auth description, replacing any marker already there
+func Mark(description, context, file string) string {
+	description = Strip(description)
+	marker := fmt.Sprintf("[vault-cli context=%s file=%s]", context, file)
+	if description == "" {
+		return marker
+	}
+	return description + " " + marker
+}
+
+// Strip removes the ownership marker from a description
+func Strip(description string) string {
+	return markerRegexp.ReplaceAllString(description, "")
+}
+
+// Parse returns the context and inventory file of the ownership marker in a
+// description
+func Parse(description string) (context, file string, ok bool) {
+	m := markerRegexp.FindStringSubmatch(description)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// Index is the sidecar kv path holding one Record per vault object written by
+// vault-cli, for the objects where vault has no room for a marker
+type Index struct {
+	svc       secretservice.SecretService
+	namespace string
+	path      string
+	mountPath string
+	v2        *bool
+}
+
+// NewIndex returns the index stored at the kv path in namespace
+func NewIndex(svc secretservice.SecretService, namespace, path string) *Index {
+	return &Index{
+		svc:       svc,
+		namespace: namespace,
+		path:      strings.Trim(path, "/"),
+	}
+}
+
+// Get returns the record of the object at path in namespace, or nil if
+// vault-cli has no record of it
+func (i *Index) Get(namespace, path string) (*Record, error) {
+	var r *Record
+	err := i.in(func() error {
+		p, err := i.apiPath("data", entryName(namespace, path))
+		if err != nil {
+			return err
+		}
+		secret, err := i.svc.Read(p)
+		if err != nil || secret == nil {
+			return err
+		}
+		r = recordFromData(i.unwrap(secret.Data))
+		return nil
+	})
+	return r, err
+}
+
+// Put stores r, replacing any record of the same object
+func (i *Index) Put(r *Record) error {
+	return i.in(func() error {
+		p, err := i.apiPath("data", entryName(r.Namespace, r.Path))
+		if err != nil {
+			return err
+		}
+		data := map[string]interface{}{
+			"kind":      r.Kind,
+			"namespace": r.Namespace,
+			"path":      strings.Trim(r.Path, "/"),
+			"file":      r.File,
+			"context":   r.Context,
+			"applied":   r.Applied.UTC().Format(time.RFC3339),
+		}
+		if *i.v2 {
+			data = map[string]interface{}{"data": data}
+		}
+		_, err = i.svc.Write(p, data)
+		return err
+	})
+}
+
+// Delete removes the record of the object at path in namespace
+func (i *Index) Delete(namespace, path string) error {
+	return i.in(func() error {
+		p, err := i.apiPath("metadata", entryName(namespace, path))
+		if err != nil {
+			return err
+		}
+		_, err = i.svc.Delete(p)
+		return err
+	})
+}
+
+// List returns every record in the index sorted by kind, namespace and path
+func (i *Index) List() ([]*Record, error) {
+	records := []*Record{}
+	err := i.in(func() error {
+		p, err := i.apiPath("metadata", "")
+		if err != nil {
+			return err
+		}
+		secret, err := i.svc.List(p)
+		if err != nil || secret == nil {
+			return err
+		}
+		keys, _ := secret.Data["keys"].([]interface{})
+		for _, k := range keys {
+			p, _ := i.apiPath("data", fmt.Sprint(k))
+			entry, err := i.svc.Read(p)
+			if err != nil {
+				return err
+			}
+			if entry != nil {
+				records = append(records, recordFromData(i.unwrap(entry.Data)))
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(records, func(a, b int) bool {
+		if records[a].Kind != records[b].Kind {
+			return records[a].Kind < records[b].Kind
+		}
+		return Key(records[a].Namespace, records[a].Path) < Key(records[b].Namespace, records[b].Path)
+	})
+	return records, err
+}
+
+// in runs fn with the client switched to the index namespace
+func (i *Index) in(fn func() error) error {
+	client := i.svc.GetClient()
+	previous := client.Headers().Get("X-Vault-Namespace")
+	client.SetNamespace(i.namespace)
+	defer client.SetNamespace(previous)
+	return fn()
+}
+
+// apiPath returns the path of the named entry, adding the kv-v2 data or
+// metadata prefix when the index is on a kv-v2 mount
+func (i *Index) apiPath(prefix, name string) (string, error) {
+	if i.v2 == nil {
+		mountPath, v2, err := i.svc.IsKVv2(i.path)
+		if err != nil {
+			return "", fmt.Errorf("unable to check ownership index %s: %s", i.path, err)
+		}
+		i.mountPath = mountPath
+		i.v2 = &v2
+	}
+	p := strings.TrimSuffix(i.path+"/"+name, "/")
+	if *i.v2 {
+		return pkgargs.AddPrefixToVKVPath(p, i.mountPath, prefix), nil
+	}
+	return p, nil
+}
+
+func (i *Index) unwrap(data map[string]interface{}) map[string]interface{} {
+	if *i.v2 {
+		inner, _ := data["data"].(map[string]interface{})
+		return inner
+	}
+	return data
+}
+
+// entryName is the index entry for an object, paths can't be used directly
+// as they hold slashes
+func entryName(namespace, path string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(Key(namespace, path))))[:16]
+}
+
+func recordFromData(data map[string]interface{}) *Record {
+	str := func(k string) string {
+		s, _ := data[k].(string)
+		return s
+	}
+	applied, _ := time.Parse(time.RFC3339, str("applied"))
+	return &Record{
+		Kind:      str("kind"),
+		Namespace: str("namespace"),
+		Path:      str("path"),
+		File:      str("file"),
+		Context:   str("context"),
+		Applied:   applied,
+	}
+}
@@ -0,0 +1,116 @@
+package owner_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/ibm/vault-cli/pkg/owner"
+	"github.com/ibm/vault-cli/pkg/secretservice/fakes"
+)
+
+func TestMark(t *testing.T) {
+	t.Parallel()
+
+	d := owner.Mark("the mount point for /pki", "ns-test", "vaultendpoint/local-root-pki")
+	if d != "the mount point for /pki [vault-cli context=ns-test file=vaultendpoint/local-root-pki]" {
+		t.Errorf("unexpected description %q", d)
+	}
+	if again := owner.Mark(d, "prod", "vaultendpoint/pki"); again != "the mount point for /pki [vault-cli context=prod file=vaultendpoint/pki]" {
+		t.Errorf("expected marker to be replaced, got %q", again)
+	}
+	if empty := owner.Mark("", "ns-test", "vaultauth/jwt"); empty != "[vault-cli context=ns-test file=vaultauth/jwt]" {
+		t.Errorf("unexpected description %q", empty)
+	}
+
+	ctx, file, ok := owner.Parse(d)
+	if !ok || ctx != "ns-test" || file != "vaultendpoint/local-root-pki" {
+		t.Errorf("unexpected parse %q %q %v", ctx, file, ok)
+	}
+	if _, _, ok := owner.Parse("hand made mount"); ok {
+		t.Errorf("expected no marker")
+	}
+	if s := owner.Strip(d); s != "the mount point for /pki" {
+		t.Errorf("unexpected strip %q", s)
+	}
+}
+
+func TestObjectPath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"/sys/mounts/pki":                 "sys/mounts/pki",
+		"sys/mounts/pki/tune":             "sys/mounts/pki",
+		"sys/auth/kubernetes/tune":        "sys/auth/kubernetes",
+		"/sys/namespaces/parent":          "sys/namespaces/parent",
+		"sys/policy/operator":             "sys/policy/operator",
+		"auth/myauth/role/operator":       "auth/myauth/role/operator",
+		"/pki/roles/tls":                  "pki/roles/tls",
+		"/pki/root/generate/internal":     "",
+		"auth/jwt/config":                 "",
+		"/ssh/config/ca":                  "",
+		"/pki/intermediate/set-signed":    "",
+		"demo/data/password":              "",
+		"sys/mounts/team/pki/int/tune":    "sys/mounts/team/pki/int",
+		"/team/pki/int/roles/server-cert": "team/pki/int/roles/server-cert",
+	}
+	for path, expected := range tests {
+		got, ok := owner.ObjectPath(path)
+		if ok != (expected != "") || got != expected {
+			t.Errorf("%s: expected %q got %q (%v)", path, expected, got, ok)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetNamespace("team")
+	svc := &fakes.FakeSecretService{}
+	svc.GetClientReturns(client)
+	svc.IsKVv2Returns("secret/", true, nil)
+
+	index := owner.NewIndex(svc, "", "secret/vault-cli/owners")
+	applied := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+	err = index.Put(&owner.Record{
+		Kind:      "vaultpolicy",
+		Namespace: "parent",
+		Path:      "sys/policy/operator",
+		File:      "parent-myauth-operator",
+		Context:   "ns-test",
+		Applied:   applied,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, data := svc.WriteArgsForCall(0)
+	if !strings.HasPrefix(path, "secret/data/vault-cli/owners/") {
+		t.Errorf("unexpected index path %s", path)
+	}
+	if client.Headers().Get("X-Vault-Namespace") != "team" {
+		t.Errorf("expected namespace to be restored")
+	}
+
+	// the same object in the root namespace spelled either way has one entry
+	svc.ReadReturns(&api.Secret{Data: data}, nil)
+	r, err := index.Get("parent", "/sys/policy/operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readPath := svc.ReadArgsForCall(0); readPath != path {
+		t.Errorf("expected read of %s got %s", path, readPath)
+	}
+	if !r.Owns("ns-test") || r.Owns("prod") || !r.Applied.Equal(applied) || r.File != "parent-myauth-operator" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	index.Get("root", "sys/namespaces/parent")
+	index.Get("", "sys/namespaces/parent")
+	if svc.ReadArgsForCall(1) != svc.ReadArgsForCall(2) {
+		t.Errorf("expected root and empty namespace to share an entry")
+	}
+}
@@ -0,0 +1,77 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// FileVersion is the version of the saved plan format
+const FileVersion = 1
+
+// File is a saved plan that apply can execute exactly
+type File struct {
+	Version  int       `json:"version"`
+	Context  string    `json:"context"`
+	Filespec string    `json:"filespec"`
+	Created  time.Time `json:"created"`
+	Changes  []*Change `json:"changes"`
+}
+
+// Save writes the plan file as indented json
+func Save(filename string, f *File) error {
+	for _, c := range f.Changes {
+		if c.Sensitive {
+			return fmt.Errorf("%s (%s): sensitive values cannot be saved in a plan file", c.Kind, c.File)
+		}
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
+
+// Load reads a plan file and checks every payload still matches its hash
+func Load(filename string) (*File, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	f := File{}
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plan file: %s", err)
+	}
+	if f.Version != FileVersion {
+		return nil, fmt.Errorf("unsupported plan file version %d", f.Version)
+	}
+	for _, c := range f.Changes {
+		if Hash(c.Data) != c.PayloadHash {
+			return nil, fmt.Errorf("%s (%s): payload for %s does not match its hash", c.Kind, c.File, c.Path)
+		}
+	}
+	return &f, nil
+}
+
+// Verify compares a saved change with the same change planned against the
+// live state now. It fails if the inventory rendered a different payload or
+// the object in vault changed since the plan was saved.
+func (c *Change) Verify(live *Change) error {
+	if live == nil {
+		return fmt.Errorf("%s (%s): %s is no longer planned from the inventory", c.Kind, c.File, c.Path)
+	}
+	if live.PayloadHash != c.PayloadHash {
+		return fmt.Errorf("%s (%s): inventory for %s changed since the plan was saved", c.Kind, c.File, c.Path)
+	}
+	if live.Fingerprint != c.Fingerprint {
+		return fmt.Errorf("%s (%s): %s changed in vault since the plan was saved", c.Kind, c.File, c.Path)
+	}
+	return nil
+}
+
+// Key identifies the object a change writes
+func (c *Change) Key() string {
+	return c.Namespace + "|" + c.Path
+}
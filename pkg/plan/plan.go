@@ -0,0 +1,253 @@
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Action is what writing a change does to the vault object
+type Action string
+
+const (
+	// ActionCreate the object does not exist yet
+	ActionCreate Action = "create"
+	// ActionUpdate the object exists and at least one field differs
+	ActionUpdate Action = "update"
+	// ActionNoChange the object exists and matches the inventory
+	ActionNoChange Action = "no-op"
+	// ActionDelete the object exists in vault but not in the inventory
+	ActionDelete Action = "delete"
+)
+
+// FieldDiff is a single field whose live value differs from the inventory
+type FieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Change is a single vault write computed from an inventory file
+type Change struct {
+	Kind        string                 `json:"kind"`
+	File        string                 `json:"file"`
+	Namespace   string                 `json:"namespace"`
+	Path        string                 `json:"path"`
+	Data        map[string]interface{} `json:"data"`
+	Sensitive   bool                   `json:"sensitive,omitempty"`
+	Action      Action                 `json:"action"`
+	Diffs       []FieldDiff            `json:"diffs,omitempty"`
+	PayloadHash string                 `json:"payloadHash"`
+	Fingerprint string                 `json:"priorFingerprint"`
+}
+
+// NewChange returns a change that writes data to path in namespace
+func NewChange(kind, file, namespace, path string, data map[string]interface{}) *Change {
+	return &Change{
+		Kind:      kind,
+		File:      file,
+		Namespace: namespace,
+		Path:      path,
+		Data:      data,
+	}
+}
+
+// NewDelete returns a change that deletes the object at path in namespace
+func NewDelete(kind, namespace, path string) *Change {
+	return &Change{
+		Kind:      kind,
+		Namespace: namespace,
+		Path:      path,
+		Action:    ActionDelete,
+	}
+}
+
+// SetCurrent compares the live state of the object with the change data and
+// sets the action, field diffs, payload hash and prior state fingerprint.
+// A nil current means the object does not exist.
+func (c *Change) SetCurrent(current map[string]interface{}) {
+	c.PayloadHash = Hash(c.Data)
+	c.Fingerprint = Hash(current)
+	if current == nil {
+		c.Action = ActionCreate
+		c.Diffs = Diff(nil, c.Data)
+		return
+	}
+	c.Diffs = Diff(current, c.Data)
+	if len(c.Diffs) == 0 {
+		c.Action = ActionNoChange
+		return
+	}
+	c.Action = ActionUpdate
+}
+
+// Diff returns the fields of desired whose value differs from current.
+// Fields only present in current are ignored as vault returns defaults for
+// everything the inventory does not set. Nested maps are compared field by
+// field and reported with dotted names.
+func Diff(current, desired map[string]interface{}) []FieldDiff {
+	return diff("", normalize(current), normalize(desired))
+}
+
+func diff(prefix string, current, desired interface{}) []FieldDiff {
+	diffs := []FieldDiff{}
+	cm, _ := current.(map[string]interface{})
+	dm, ok := desired.(map[string]interface{})
+	if !ok {
+		return diffs
+	}
+	keys := make([]string, 0, len(dm))
+	for k := range dm {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		field := prefix + k
+		dv := dm[k]
+		cv, found := cm[k]
+		if _, isMap := dv.(map[string]interface{}); isMap {
+			diffs = append(diffs, diff(field+".", cv, dv)...)
+			continue
+		}
+		if found && equal(cv, dv) || !found && isEmpty(dv) {
+			continue
+		}
+		diffs = append(diffs, FieldDiff{Field: field, Old: cv, New: dv})
+	}
+	return diffs
+}
+
+// normalize round trips v through json so numbers, lists and maps have the
+// same go types whether they came from vault or from the inventory
+func normalize(v map[string]interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// equal compares two normalized values allowing for the representations
+// vault uses for lists, durations and empty values
+func equal(current, desired interface{}) bool {
+	if reflect.DeepEqual(current, desired) {
+		return true
+	}
+	if isEmpty(current) && isEmpty(desired) {
+		return true
+	}
+	switch d := desired.(type) {
+	case string:
+		switch c := current.(type) {
+		case []interface{}:
+			return joinList(c) == d
+		case float64:
+			secs, ok := seconds(d)
+			return ok && secs == c
+		case string:
+			return strings.TrimSpace(c) == strings.TrimSpace(d)
+		}
+	case float64:
+		if c, ok := current.(string); ok {
+			secs, ok := seconds(c)
+			return ok && secs == d
+		}
+	case []interface{}:
+		if c, ok := current.(string); ok {
+			return joinList(d) == c
+		}
+	}
+	return false
+}
+
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case float64:
+		return t == 0
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+func joinList(l []interface{}) string {
+	s := make([]string, len(l))
+	for i, v := range l {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// seconds parses vault duration strings such as "30m" or "3600"
+func seconds(s string) (float64, bool) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, true
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d.Seconds(), true
+	}
+	return 0, false
+}
+
+// Hash returns the sha256 of the normalized json encoding of v, or an empty
+// string when v is nil. encoding/json sorts map keys so equal maps hash equally.
+func Hash(v map[string]interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(normalize(v))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// Summary counts the changes by action
+func Summary(changes []*Change) map[Action]int {
+	summary := map[Action]int{}
+	for _, c := range changes {
+		summary[c.Action]++
+	}
+	return summary
+}
+
+// SensitiveValue replaces the values of sensitive changes in Redacted
+const SensitiveValue = "(sensitive value)"
+
+// Redacted returns copies of the changes fit for printing. Sensitive changes
+// lose their payload and their diffs show SensitiveValue instead of values.
+func Redacted(changes []*Change) []*Change {
+	redacted := make([]*Change, 0, len(changes))
+	for _, c := range changes {
+		r := *c
+		if c.Sensitive {
+			r.Data = nil
+			r.Diffs = make([]FieldDiff, len(c.Diffs))
+			for i, d := range c.Diffs {
+				r.Diffs[i] = FieldDiff{Field: d.Field, Old: SensitiveValue, New: SensitiveValue}
+			}
+		}
+		redacted = append(redacted, &r)
+	}
+	return redacted
+}
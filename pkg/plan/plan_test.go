@@ -0,0 +1,153 @@
+package plan_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ibm/vault-cli/pkg/plan"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	current := map[string]interface{}{
+		"allowed_users":     "*",
+		"token_policies":    []interface{}{"operator"},
+		"token_bound_cidrs": []interface{}{"10.0.0.0/8", "192.168.0.0/16"},
+		"ttl":               json.Number("1800"),
+		"max_ttl":           json.Number("36000"),
+		"key_bits":          json.Number("2048"),
+		"description":       "",
+		"config": map[string]interface{}{
+			"default_lease_ttl": json.Number("60"),
+			"max_lease_ttl":     json.Number("120"),
+		},
+		"only_in_vault": true,
+	}
+	desired := map[string]interface{}{
+		"allowed_users":     "*",
+		"token_policies":    []string{"operator"},
+		"token_bound_cidrs": "10.0.0.0/8,192.168.0.0/16",
+		"ttl":               "30m0s",
+		"max_ttl":           "36000",
+		"key_bits":          4096,
+		"default_user":      "ubuntu",
+		"config": map[string]interface{}{
+			"default_lease_ttl": 60,
+			"max_lease_ttl":     240,
+		},
+	}
+
+	diffs := plan.Diff(current, desired)
+	want := []string{"config.max_lease_ttl", "default_user", "key_bits"}
+	if len(diffs) != len(want) {
+		t.Fatalf("expected %d diffs got %d: %v", len(want), len(diffs), diffs)
+	}
+	for i, d := range diffs {
+		if d.Field != want[i] {
+			t.Errorf("expected diff %d to be %q got %q", i, want[i], d.Field)
+		}
+	}
+}
+
+func TestChangeSetCurrent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		current map[string]interface{}
+		exp     plan.Action
+	}{
+		{"missing", nil, plan.ActionCreate},
+		{"same", map[string]interface{}{"policy": "path \"a\" {}\n"}, plan.ActionNoChange},
+		{"different", map[string]interface{}{"policy": "path \"b\" {}"}, plan.ActionUpdate},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := plan.NewChange("vaultpolicy", "f", "", "sys/policy/p", map[string]interface{}{"policy": "path \"a\" {}"})
+			c.SetCurrent(tc.current)
+			if c.Action != tc.exp {
+				t.Errorf("expected %q to be %q", c.Action, tc.exp)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "plan.json")
+
+	c := plan.NewChange("vaultnamespace", "1-parent", "root", "sys/namespaces/parent", map[string]interface{}{})
+	c.SetCurrent(nil)
+	err = plan.Save(filename, &plan.File{Version: plan.FileVersion, Context: "ns-test", Changes: []*plan.Change{c}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := plan.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Changes) != 1 {
+		t.Fatalf("expected 1 change got %d", len(f.Changes))
+	}
+	if err := f.Changes[0].Verify(c); err != nil {
+		t.Errorf("expected saved change to verify: %s", err)
+	}
+
+	live := plan.NewChange("vaultnamespace", "1-parent", "root", "sys/namespaces/parent", map[string]interface{}{})
+	live.SetCurrent(map[string]interface{}{"id": "abc"})
+	if err := f.Changes[0].Verify(live); err == nil {
+		t.Errorf("expected live state change to fail verification")
+	}
+
+	secret := plan.NewChange("secretmeta", "demo-password", "", "demo/data/password", map[string]interface{}{"password": "foo"})
+	secret.Sensitive = true
+	err = plan.Save(filename, &plan.File{Version: plan.FileVersion, Changes: []*plan.Change{secret}})
+	if err == nil {
+		t.Errorf("expected sensitive change to be refused")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	t.Parallel()
+
+	secret := plan.NewChange("secretmeta", "demo-password", "", "demo/data/password", map[string]interface{}{"password": "foo"})
+	secret.Sensitive = true
+	secret.SetCurrent(map[string]interface{}{"password": "bar"})
+	policy := plan.NewChange("vaultpolicy", "parent-operator", "parent", "sys/policy/operator", map[string]interface{}{"policy": "p"})
+	policy.SetCurrent(nil)
+
+	redacted := plan.Redacted([]*plan.Change{secret, policy})
+	out, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "foo") || strings.Contains(string(out), "bar") {
+		t.Errorf("sensitive values printed: %s", out)
+	}
+	if len(redacted[0].Diffs) != 1 || redacted[0].Diffs[0].New != plan.SensitiveValue {
+		t.Errorf("unexpected diffs %+v", redacted[0].Diffs)
+	}
+	if redacted[1].Data["policy"] != "p" {
+		t.Errorf("expected policy payload to be kept")
+	}
+	if secret.Data["password"] != "foo" {
+		t.Errorf("expected the change itself to be unchanged")
+	}
+}
@@ -15,7 +15,4 @@ type SecretService interface {
 	IsKVv2(path string) (string, bool, error)
 	GetClient() *api.Client
 	SetClient(c *api.Client)
-	AppRoleLogin(namespace, authurl, endpoint, roleID, secretID, cacert string, insecureSkipVerify bool) (*api.Secret, error)
-	CertLogin(namespace, url, endpoint, cert, key, cacert string, insecureSkipVerify bool) (*api.Secret, error)
-	UserPassLogin(namespace, authurl, endpoint, username, password, cacert string, insecureSkipVerify bool) (*api.Secret, error)
 }